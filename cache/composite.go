@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// Composite draws the images of plan.Values (in the same order) and crops them to the requested region. The result is
+// typically passed to the RenderRegion method of a render.Renderer to apply the size, rotation, and quality of the
+// request.
+func Composite(plan Plan, images []image.Image) (image.Image, error) {
+	if plan.Strategy == StrategySource {
+		return nil, errors.New("plan requires the source image")
+	} else if len(images) != len(plan.Values) {
+		return nil, fmt.Errorf("expected %d images but got %d", len(plan.Values), len(images))
+	}
+
+	switch plan.Strategy {
+	case StrategyExact:
+		return images[0], nil
+	case StrategyDerivative:
+		return cropImage(images[0], plan.Crop), nil
+	}
+
+	var bounds image.Rectangle
+
+	for valueIdx, value := range plan.Values {
+		at := image.Pt(int((value.Region[0]-plan.Origin[0])/plan.ScaleFactor), int((value.Region[1]-plan.Origin[1])/plan.ScaleFactor))
+		bounds = bounds.Union(image.Rectangle{Min: at, Max: at.Add(images[valueIdx].Bounds().Size())})
+	}
+
+	mosaic := image.NewRGBA(bounds)
+
+	for valueIdx, value := range plan.Values {
+		at := image.Pt(int((value.Region[0]-plan.Origin[0])/plan.ScaleFactor), int((value.Region[1]-plan.Origin[1])/plan.ScaleFactor))
+		img := images[valueIdx]
+
+		draw.Draw(mosaic, image.Rectangle{Min: at, Max: at.Add(img.Bounds().Size())}, img, img.Bounds().Min, draw.Src)
+	}
+
+	return mosaic.SubImage(plan.Crop.Intersect(mosaic.Bounds())), nil
+}
+
+func cropImage(img image.Image, crop image.Rectangle) image.Image {
+	crop = crop.Add(img.Bounds().Min).Intersect(img.Bounds())
+
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(crop)
+	}
+
+	out := image.NewRGBA(image.Rectangle{Max: crop.Size()})
+	draw.Draw(out, out.Bounds(), img, crop.Min, draw.Src)
+
+	return out
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// Index is the set of region+size values which have already been rendered for an image. It is safe for concurrent
+// use.
+type Index struct {
+	mu     sync.RWMutex
+	values map[pixelset.Value]struct{}
+}
+
+var _ pixelset.ValueDomain = &Index{}
+
+// NewIndex creates an index of values.
+func NewIndex(values ...pixelset.Value) *Index {
+	idx := &Index{
+		values: map[pixelset.Value]struct{}{},
+	}
+
+	for _, value := range values {
+		idx.values[value] = struct{}{}
+	}
+
+	return idx
+}
+
+// Add records that value has been rendered.
+func (idx *Index) Add(value pixelset.Value) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.values[value] = struct{}{}
+}
+
+// Remove records that value is no longer available (e.g. evicted).
+func (idx *Index) Remove(value pixelset.Value) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	delete(idx.values, value)
+}
+
+func (idx *Index) Contains(value pixelset.Value) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	_, ok := idx.values[value]
+
+	return ok
+}
+
+// Enumerate returns the values of the index, sorted by region and then size.
+func (idx *Index) Enumerate() pixelset.ValueList {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	values := make(pixelset.ValueList, 0, len(idx.values))
+
+	for value := range idx.values {
+		values = append(values, value)
+	}
+
+	values.Sort()
+
+	return values
+}
@@ -0,0 +1,3 @@
+// cache offers a planner for answering image requests from previously rendered derivatives (e.g. an exact hit, a larger
+// derivative to crop and scale, or a mosaic of tiles) rather than the original source image.
+package cache
@@ -0,0 +1,323 @@
+package cache
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// Strategy describes how a request is answered.
+type Strategy string
+
+const (
+	// StrategyExact means a cached value has the same region+size as the request.
+	StrategyExact Strategy = "exact"
+
+	// StrategyDerivative means a single cached value contains the region at sufficient detail to be cropped and scaled.
+	StrategyDerivative Strategy = "derivative"
+
+	// StrategyMosaic means several cached values with the same scale factor (typically tiles) cover the region and
+	// are composited before being cropped and scaled.
+	StrategyMosaic Strategy = "mosaic"
+
+	// StrategySource means the request must be rendered from the source image.
+	StrategySource Strategy = "source"
+)
+
+// Plan is the result of [Planner.Plan]. Only the region and size of a request are considered, so any rotation or
+// quality must still be applied to the result.
+type Plan struct {
+	Strategy Strategy
+
+	// Values are the cached values to read. There is one for exact and derivative plans and several for mosaics.
+	Values pixelset.ValueList
+
+	// ScaleFactor is the reduction of a mosaic compared to the full image. Each value is drawn at its region divided
+	// by the scale factor, relative to Origin.
+	ScaleFactor uint32
+
+	// Origin is the full image position of the top-left corner of a mosaic.
+	Origin [2]uint32
+
+	// Crop is the area of the derivative or mosaic which corresponds to the requested region, expanded to whole
+	// pixels. It is empty for exact and source plans.
+	Crop image.Rectangle
+
+	// Cost is the estimated number of pixels decoded, including [Planner.FileCost] for each value.
+	Cost float64
+}
+
+// Planner finds the cheapest way to answer requests from cached values.
+type Planner struct {
+	// FileCost is the overhead of reading a single value, in pixels. It favors derivatives over mosaics of many small
+	// values. If zero, 4096 is used.
+	FileCost float64
+}
+
+func (p Planner) getFileCost() float64 {
+	if p.FileCost > 0 {
+		return p.FileCost
+	}
+
+	return 4096
+}
+
+// Plan finds the cheapest way to answer the region+size of params from cached. Any [pixelset.ValueDomain] may be used,
+// such as an [Index] or the domain of a fully exported image.
+func (p Planner) Plan(params imagerequest.ResolvedParams, cached pixelset.ValueDomain) Plan {
+	return p.PlanValue(pixelset.Value{
+		Region: params.RegionPixels(),
+		Size:   params.SizePixels(),
+	}, cached)
+}
+
+// PlanValue is the same as [Planner.Plan] for a region+size value.
+func (p Planner) PlanValue(requested pixelset.Value, cached pixelset.ValueDomain) Plan {
+	if cached.Contains(requested) {
+		return Plan{
+			Strategy: StrategyExact,
+			Values:   pixelset.ValueList{requested},
+			Cost:     valueArea(requested) + p.getFileCost(),
+		}
+	}
+
+	// the detail required of cached values; upscaled requests are satisfied by the full resolution
+	need := [2]float64{
+		math.Min(float64(requested.Size[0])/float64(requested.Region[2]), 1),
+		math.Min(float64(requested.Size[1])/float64(requested.Region[3]), 1),
+	}
+
+	best := Plan{
+		Strategy: StrategySource,
+	}
+
+	groups := map[uint32]pixelset.ValueList{}
+
+	for _, value := range cached.Enumerate() {
+		if value.Size[0] == 0 || value.Size[1] == 0 || !intersects(value.Region, requested.Region) {
+			continue
+		} else if !sufficientDetail(value, need) {
+			continue
+		}
+
+		if containsRegion(value.Region, requested.Region) {
+			cost := valueArea(value) + p.getFileCost()
+
+			if best.Strategy == StrategySource || cost < best.Cost {
+				best = Plan{
+					Strategy: StrategyDerivative,
+					Values:   pixelset.ValueList{value},
+					Crop: scaledCrop(
+						requested.Region,
+						[2]uint32{value.Region[0], value.Region[1]},
+						[2]float64{float64(value.Size[0]) / float64(value.Region[2]), float64(value.Size[1]) / float64(value.Region[3])},
+					),
+					Cost: cost,
+				}
+			}
+		}
+
+		if scaleFactor, ok := valueScaleFactor(value); ok {
+			groups[scaleFactor] = append(groups[scaleFactor], value)
+		}
+	}
+
+	scaleFactors := make([]uint32, 0, len(groups))
+
+	for scaleFactor := range groups {
+		scaleFactors = append(scaleFactors, scaleFactor)
+	}
+
+	sort.Slice(scaleFactors, func(i, j int) bool {
+		return scaleFactors[i] < scaleFactors[j]
+	})
+
+	for _, scaleFactor := range scaleFactors {
+		values, ok := coveringValues(requested.Region, groups[scaleFactor])
+		if !ok || len(values) < 2 {
+			// single values were already considered as derivatives
+			continue
+		}
+
+		var cost float64
+
+		origin := [2]uint32{math.MaxUint32, math.MaxUint32}
+
+		for _, value := range values {
+			cost += valueArea(value) + p.getFileCost()
+
+			if value.Region[0] < origin[0] {
+				origin[0] = value.Region[0]
+			}
+
+			if value.Region[1] < origin[1] {
+				origin[1] = value.Region[1]
+			}
+		}
+
+		if best.Strategy != StrategySource && cost >= best.Cost {
+			continue
+		}
+
+		best = Plan{
+			Strategy:    StrategyMosaic,
+			Values:      values,
+			ScaleFactor: scaleFactor,
+			Origin:      origin,
+			Crop:        scaledCrop(requested.Region, origin, [2]float64{1 / float64(scaleFactor), 1 / float64(scaleFactor)}),
+			Cost:        cost,
+		}
+	}
+
+	return best
+}
+
+func valueArea(value pixelset.Value) float64 {
+	return float64(value.Size[0]) * float64(value.Size[1])
+}
+
+func intersects(a, b [4]uint32) bool {
+	return a[0] < b[0]+b[2] && b[0] < a[0]+a[2] && a[1] < b[1]+b[3] && b[1] < a[1]+a[3]
+}
+
+func containsRegion(outer, inner [4]uint32) bool {
+	return outer[0] <= inner[0] && outer[1] <= inner[1] && outer[0]+outer[2] >= inner[0]+inner[2] && outer[1]+outer[3] >= inner[1]+inner[3]
+}
+
+func sufficientDetail(value pixelset.Value, need [2]float64) bool {
+	const epsilon = 1e-9
+
+	return float64(value.Size[0])/float64(value.Region[2]) >= need[0]-epsilon &&
+		float64(value.Size[1])/float64(value.Region[3]) >= need[1]-epsilon
+}
+
+// valueScaleFactor returns the integer scale factor of value if it could be a tile of a grid (i.e. its size is the
+// rounded-up region divided by the scale factor and it is aligned to the scaled pixels).
+func valueScaleFactor(value pixelset.Value) (uint32, bool) {
+	base := value.Region[2] / value.Size[0]
+
+	for _, scaleFactor := range []uint32{base, base + 1} {
+		if scaleFactor == 0 {
+			continue
+		} else if (value.Region[2]+scaleFactor-1)/scaleFactor != value.Size[0] || (value.Region[3]+scaleFactor-1)/scaleFactor != value.Size[1] {
+			continue
+		} else if value.Region[0]%scaleFactor != 0 || value.Region[1]%scaleFactor != 0 {
+			continue
+		}
+
+		return scaleFactor, true
+	}
+
+	return 0, false
+}
+
+// coveringValues greedily selects the largest values until region is fully covered. The result is ordered by row and
+// then column.
+func coveringValues(region [4]uint32, values pixelset.ValueList) (pixelset.ValueList, bool) {
+	xs := []uint32{region[0], region[0] + region[2]}
+	ys := []uint32{region[1], region[1] + region[3]}
+
+	for _, value := range values {
+		xs = append(xs, clampUint32(value.Region[0], region[0], region[0]+region[2]), clampUint32(value.Region[0]+value.Region[2], region[0], region[0]+region[2]))
+		ys = append(ys, clampUint32(value.Region[1], region[1], region[1]+region[3]), clampUint32(value.Region[1]+value.Region[3], region[1], region[1]+region[3]))
+	}
+
+	xs = uniqueSortedUint32(xs)
+	ys = uniqueSortedUint32(ys)
+
+	covered := make([]bool, (len(xs)-1)*(len(ys)-1))
+	remaining := len(covered)
+
+	candidates := append(pixelset.ValueList(nil), values...)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return uint64(candidates[i].Region[2])*uint64(candidates[i].Region[3]) > uint64(candidates[j].Region[2])*uint64(candidates[j].Region[3])
+	})
+
+	var selected pixelset.ValueList
+
+	for _, value := range candidates {
+		var added bool
+
+		for yi := 0; yi < len(ys)-1; yi++ {
+			if ys[yi] < value.Region[1] || ys[yi+1] > value.Region[1]+value.Region[3] {
+				continue
+			}
+
+			for xi := 0; xi < len(xs)-1; xi++ {
+				if xs[xi] < value.Region[0] || xs[xi+1] > value.Region[0]+value.Region[2] {
+					continue
+				}
+
+				cell := yi*(len(xs)-1) + xi
+				if covered[cell] {
+					continue
+				}
+
+				covered[cell] = true
+				remaining--
+				added = true
+			}
+		}
+
+		if added {
+			selected = append(selected, value)
+		}
+
+		if remaining == 0 {
+			break
+		}
+	}
+
+	if remaining > 0 {
+		return nil, false
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Region[1] != selected[j].Region[1] {
+			return selected[i].Region[1] < selected[j].Region[1]
+		}
+
+		return selected[i].Region[0] < selected[j].Region[0]
+	})
+
+	return selected, true
+}
+
+func scaledCrop(region [4]uint32, origin [2]uint32, scale [2]float64) image.Rectangle {
+	return image.Rect(
+		int(math.Floor(float64(region[0]-origin[0])*scale[0])),
+		int(math.Floor(float64(region[1]-origin[1])*scale[1])),
+		int(math.Ceil(float64(region[0]+region[2]-origin[0])*scale[0])),
+		int(math.Ceil(float64(region[1]+region[3]-origin[1])*scale[1])),
+	)
+}
+
+func clampUint32(v, min, max uint32) uint32 {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+
+	return v
+}
+
+func uniqueSortedUint32(values []uint32) []uint32 {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+
+	var unique []uint32
+
+	for _, v := range values {
+		if len(unique) == 0 || unique[len(unique)-1] != v {
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
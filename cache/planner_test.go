@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+func exampleImageInformation() iiifimageapi.ImageInformation {
+	return iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   100,
+		Height:  60,
+		Sizes: []iiifimageapi.ImageInformationSize{
+			{Width: 50, Height: 30},
+		},
+		Tiles: []iiifimageapi.ImageInformationTile{
+			{Width: 32, ScaleFactors: []uint32{1, 2, 4}},
+		},
+	})
+}
+
+func exampleIndex() *Index {
+	return NewIndex(pixelset.NewImageDomain(exampleImageInformation()).Enumerate()...)
+}
+
+func TestPlanner_Exact(t *testing.T) {
+	plan := Planner{}.PlanValue(pixelset.Value{Region: [4]uint32{0, 0, 100, 60}, Size: [2]uint32{50, 30}}, exampleIndex())
+
+	if _e, _a := StrategyExact, plan.Strategy; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestPlanner_Derivative(t *testing.T) {
+	raw, _ := imagerequest.RawParamsFromString("full/40,/0/default.jpg")
+	parsed, _ := imagerequest.ParseRawParams(raw)
+
+	params, err := parsed.Resolve(imagerequest.ResolveOptions{
+		ImageInformation:    exampleImageInformation(),
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	plan := Planner{}.Plan(params, exampleIndex())
+
+	if _e, _a := StrategyDerivative, plan.Strategy; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (pixelset.ValueList{{Region: [4]uint32{0, 0, 100, 60}, Size: [2]uint32{50, 30}}}), plan.Values; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := image.Rect(0, 0, 50, 30), plan.Crop; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestPlanner_DerivativeCrop(t *testing.T) {
+	plan := Planner{}.PlanValue(pixelset.Value{Region: [4]uint32{70, 10, 20, 20}, Size: [2]uint32{10, 10}}, exampleIndex())
+
+	if _e, _a := StrategyDerivative, plan.Strategy; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (pixelset.ValueList{{Region: [4]uint32{64, 0, 36, 60}, Size: [2]uint32{18, 30}}}), plan.Values; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := image.Rect(3, 5, 13, 15), plan.Crop; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestPlanner_Mosaic(t *testing.T) {
+	plan := Planner{}.PlanValue(pixelset.Value{Region: [4]uint32{10, 10, 60, 40}, Size: [2]uint32{60, 40}}, exampleIndex())
+
+	if _e, _a := StrategyMosaic, plan.Strategy; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 6, len(plan.Values); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(1), plan.ScaleFactor; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := [2]uint32{0, 0}, plan.Origin; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := image.Rect(10, 10, 70, 50), plan.Crop; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestPlanner_Source(t *testing.T) {
+	idx := exampleIndex()
+
+	for _, tc := range []struct {
+		name  string
+		value pixelset.Value
+		index pixelset.ValueDomain
+	}{
+		{
+			name:  "empty",
+			value: pixelset.Value{Region: [4]uint32{0, 0, 100, 60}, Size: [2]uint32{50, 30}},
+			index: NewIndex(),
+		},
+		{
+			name:  "insufficient detail",
+			value: pixelset.Value{Region: [4]uint32{0, 0, 100, 60}, Size: [2]uint32{200, 120}},
+			index: NewIndex(pixelset.Value{Region: [4]uint32{0, 0, 100, 60}, Size: [2]uint32{50, 30}}),
+		},
+		{
+			name:  "incomplete mosaic",
+			value: pixelset.Value{Region: [4]uint32{10, 10, 60, 40}, Size: [2]uint32{60, 40}},
+			index: func() *Index {
+				idx.Remove(pixelset.Value{Region: [4]uint32{32, 32, 32, 28}, Size: [2]uint32{32, 28}})
+
+				return idx
+			}(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := Planner{}.PlanValue(tc.value, tc.index)
+
+			if _e, _a := StrategySource, plan.Strategy; _e != _a {
+				t.Fatalf("expected `%v` but got: %v", _e, _a)
+			}
+		})
+	}
+}
+
+func TestComposite_Mosaic(t *testing.T) {
+	plan := Planner{}.PlanValue(pixelset.Value{Region: [4]uint32{16, 16, 32, 32}, Size: [2]uint32{32, 32}}, exampleIndex())
+
+	colors := []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}, {R: 255, G: 255, A: 255}}
+
+	var images []image.Image
+
+	for valueIdx, value := range plan.Values {
+		img := image.NewRGBA(image.Rect(0, 0, int(value.Size[0]), int(value.Size[1])))
+
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = colors[valueIdx].R, colors[valueIdx].G, colors[valueIdx].B, colors[valueIdx].A
+		}
+
+		images = append(images, img)
+	}
+
+	img, err := Composite(plan, images)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := image.Pt(32, 32), img.Bounds().Size(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, tc := range []struct {
+		at    image.Point
+		color color.RGBA
+	}{
+		{at: image.Pt(0, 0), color: colors[0]},
+		{at: image.Pt(31, 0), color: colors[1]},
+		{at: image.Pt(0, 31), color: colors[2]},
+		{at: image.Pt(31, 31), color: colors[3]},
+	} {
+		if _e, _a := color.Color(tc.color), img.At(img.Bounds().Min.X+tc.at.X, img.Bounds().Min.Y+tc.at.Y); _e != _a {
+			t.Fatalf("%v: expected `%v` but got: %v", tc.at, _e, _a)
+		}
+	}
+}
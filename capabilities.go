@@ -0,0 +1,250 @@
+package iiifimageapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dpb587/go-iiif-image-api-v3/internal/uint32ptr"
+)
+
+// CapabilityLimits are the largest outputs an image processor is able to render. Nil values are unlimited.
+type CapabilityLimits struct {
+	MaxWidth  *uint32
+	MaxHeight *uint32
+	MaxArea   *uint32
+}
+
+// Capabilities is implemented by image processors to declare what they actually support. It is the source for the
+// advertised fields of [ImageInformation] (see [ApplyCapabilities] and [CheckCapabilities]).
+type Capabilities interface {
+	SupportedFeatures() FeatureNameList
+	SupportedQualities() []string
+	SupportedFormats() []string
+	PreferredFormats() []string
+	Limits() CapabilityLimits
+}
+
+// StaticCapabilities is a [Capabilities] with fixed values.
+type StaticCapabilities struct {
+	Features  FeatureNameList
+	Qualities []string
+	Formats   []string
+	Preferred []string
+	Max       CapabilityLimits
+}
+
+var _ Capabilities = StaticCapabilities{}
+
+func (c StaticCapabilities) SupportedFeatures() FeatureNameList {
+	return c.Features
+}
+
+func (c StaticCapabilities) SupportedQualities() []string {
+	return c.Qualities
+}
+
+func (c StaticCapabilities) SupportedFormats() []string {
+	return c.Formats
+}
+
+func (c StaticCapabilities) PreferredFormats() []string {
+	return c.Preferred
+}
+
+func (c StaticCapabilities) Limits() CapabilityLimits {
+	return c.Max
+}
+
+// ApplyCapabilities returns a copy of info with the profile, extra features, extra qualities, extra formats, preferred
+// formats, and max constraints derived from caps. Existing max constraints are kept when they are tighter.
+func ApplyCapabilities(info ImageInformation, caps Capabilities) (ImageInformation, error) {
+	dp, err := DeriveProfile(ProfileCapabilities{
+		Features:  caps.SupportedFeatures(),
+		Qualities: caps.SupportedQualities(),
+		Formats:   caps.SupportedFormats(),
+	})
+	if err != nil {
+		return ImageInformation{}, err
+	}
+
+	info = dp.Apply(info)
+	info.PreferredFormats = caps.PreferredFormats()
+
+	limits := caps.Limits()
+
+	info.MaxWidth = uint32ptr.Min(info.MaxWidth, limits.MaxWidth)
+	info.MaxHeight = uint32ptr.Min(info.MaxHeight, limits.MaxHeight)
+	info.MaxArea = uint32ptr.Min(info.MaxArea, limits.MaxArea)
+
+	return info, nil
+}
+
+// CapabilityMismatchError describes the differences between advertised [ImageInformation] and [Capabilities].
+type CapabilityMismatchError struct {
+	// UnsupportedFeatures, UnsupportedQualities, and UnsupportedFormats are advertised but not supported.
+	UnsupportedFeatures  FeatureNameList
+	UnsupportedQualities []string
+	UnsupportedFormats   []string
+
+	// UnadvertisedFeatures, UnadvertisedQualities, and UnadvertisedFormats are supported but not advertised.
+	UnadvertisedFeatures  FeatureNameList
+	UnadvertisedQualities []string
+	UnadvertisedFormats   []string
+
+	// ExceededLimits describes advertised max constraints which exceed (or are missing for) the limits.
+	ExceededLimits []string
+}
+
+func (e CapabilityMismatchError) Error() string {
+	var issues []string
+
+	if len(e.UnsupportedFeatures) > 0 {
+		issues = append(issues, fmt.Sprintf("unsupported features %v", e.UnsupportedFeatures))
+	}
+
+	if len(e.UnsupportedQualities) > 0 {
+		issues = append(issues, fmt.Sprintf("unsupported qualities %v", e.UnsupportedQualities))
+	}
+
+	if len(e.UnsupportedFormats) > 0 {
+		issues = append(issues, fmt.Sprintf("unsupported formats %v", e.UnsupportedFormats))
+	}
+
+	if len(e.UnadvertisedFeatures) > 0 {
+		issues = append(issues, fmt.Sprintf("unadvertised features %v", e.UnadvertisedFeatures))
+	}
+
+	if len(e.UnadvertisedQualities) > 0 {
+		issues = append(issues, fmt.Sprintf("unadvertised qualities %v", e.UnadvertisedQualities))
+	}
+
+	if len(e.UnadvertisedFormats) > 0 {
+		issues = append(issues, fmt.Sprintf("unadvertised formats %v", e.UnadvertisedFormats))
+	}
+
+	issues = append(issues, e.ExceededLimits...)
+
+	return "capabilities mismatch: " + strings.Join(issues, "; ")
+}
+
+func (e CapabilityMismatchError) isEmpty() bool {
+	return len(e.UnsupportedFeatures) == 0 && len(e.UnsupportedQualities) == 0 && len(e.UnsupportedFormats) == 0 &&
+		len(e.UnadvertisedFeatures) == 0 && len(e.UnadvertisedQualities) == 0 && len(e.UnadvertisedFormats) == 0 &&
+		len(e.ExceededLimits) == 0
+}
+
+// CheckCapabilities ensures that everything advertised by info (including the base features, qualities, and formats
+// of its profile) is supported by caps, and that everything supported is advertised. It is intended to be called
+// during startup. If levels is nil, [DefaultComplianceLevels] will be used. A [CapabilityMismatchError] is returned
+// when they do not agree.
+func CheckCapabilities(info ImageInformation, caps Capabilities, levels ComplianceLevels) error {
+	if levels == nil {
+		levels = DefaultComplianceLevels
+	}
+
+	spec, ok := levels.GetByName(info.Profile)
+	if !ok {
+		return fmt.Errorf("profile (%s) is not supported", info.Profile)
+	}
+
+	var mismatch CapabilityMismatchError
+
+	{ // features
+		advertised := featureNameSet(append(append(FeatureNameList(nil), spec.BaseFeatures()...), info.ExtraFeatures...))
+		supported := featureNameSet(caps.SupportedFeatures())
+
+		for _, feature := range sortedFeatureNames(advertised) {
+			if _, ok := supported[feature]; !ok {
+				mismatch.UnsupportedFeatures = append(mismatch.UnsupportedFeatures, feature)
+			}
+		}
+
+		for _, feature := range sortedFeatureNames(supported) {
+			if _, ok := advertised[feature]; !ok {
+				mismatch.UnadvertisedFeatures = append(mismatch.UnadvertisedFeatures, feature)
+			}
+		}
+	}
+
+	{ // qualities
+		advertised := stringSet(append(append([]string(nil), spec.BaseQualities()...), info.ExtraQualities...))
+		supported := stringSet(append([]string{"default"}, caps.SupportedQualities()...))
+
+		mismatch.UnsupportedQualities = missingStrings(advertised, supported)
+		mismatch.UnadvertisedQualities = missingStrings(supported, advertised)
+	}
+
+	{ // formats
+		advertised := stringSet(append(append([]string(nil), spec.BaseFormats()...), info.ExtraFormats...))
+		supported := stringSet(caps.SupportedFormats())
+
+		mismatch.UnsupportedFormats = missingStrings(advertised, supported)
+		mismatch.UnadvertisedFormats = missingStrings(supported, advertised)
+
+		for _, format := range info.PreferredFormats {
+			if _, ok := supported[format]; ok {
+				continue
+			} else if _, ok := advertised[format]; ok {
+				// already reported
+				continue
+			}
+
+			mismatch.UnsupportedFormats = append(mismatch.UnsupportedFormats, format)
+		}
+	}
+
+	{ // limits
+		limits := caps.Limits()
+
+		for _, check := range []struct {
+			name       string
+			advertised *uint32
+			limit      *uint32
+		}{
+			{"maxWidth", info.MaxWidth, limits.MaxWidth},
+			{"maxHeight", info.MaxHeight, limits.MaxHeight},
+			{"maxArea", info.MaxArea, limits.MaxArea},
+		} {
+			if check.limit == nil {
+				continue
+			} else if check.advertised == nil {
+				mismatch.ExceededLimits = append(mismatch.ExceededLimits, fmt.Sprintf("%s is unset (limit %d)", check.name, *check.limit))
+			} else if *check.advertised > *check.limit {
+				mismatch.ExceededLimits = append(mismatch.ExceededLimits, fmt.Sprintf("%s (%d) exceeds limit (%d)", check.name, *check.advertised, *check.limit))
+			}
+		}
+	}
+
+	if mismatch.isEmpty() {
+		return nil
+	}
+
+	return mismatch
+}
+
+func sortedFeatureNames(set map[FeatureName]struct{}) FeatureNameList {
+	var out FeatureNameList
+
+	for feature := range set {
+		out = append(out, feature)
+	}
+
+	out.Sort()
+
+	return out
+}
+
+func missingStrings(set map[string]struct{}, from map[string]struct{}) []string {
+	var out []string
+
+	for s := range set {
+		if _, ok := from[s]; !ok {
+			out = append(out, s)
+		}
+	}
+
+	sort.Strings(out)
+
+	return out
+}
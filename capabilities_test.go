@@ -0,0 +1,72 @@
+package iiifimageapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func exampleCapabilities() StaticCapabilities {
+	maxWidth := uint32(4096)
+
+	return StaticCapabilities{
+		Features:  append(FeatureNameList{FeatureNameMirroring}, complianceLevel2Spec.BaseFeatures()...),
+		Qualities: []string{"color", "gray"},
+		Formats:   []string{"jpg", "png", "webp"},
+		Preferred: []string{"webp"},
+		Max: CapabilityLimits{
+			MaxWidth: &maxWidth,
+		},
+	}
+}
+
+func TestApplyCapabilities(t *testing.T) {
+	info, err := ApplyCapabilities(ImageInformation{Width: 8000, Height: 6000}, exampleCapabilities())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := ComplianceLevel2Name, info.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (FeatureNameList{FeatureNameMirroring}), info.ExtraFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"webp"}, info.ExtraFormats; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"webp"}, info.PreferredFormats; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if info.MaxWidth == nil {
+		t.Fatal("expected value but got `nil`")
+	} else if _e, _a := uint32(4096), *info.MaxWidth; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if err := CheckCapabilities(info, exampleCapabilities(), nil); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+}
+
+func TestCheckCapabilities_Mismatch(t *testing.T) {
+	maxWidth := uint32(10000)
+
+	err := CheckCapabilities(
+		ImageInformation{
+			Profile:        ComplianceLevel2Name,
+			ExtraFeatures:  FeatureNameList{FeatureNameRotationArbitrary},
+			ExtraFormats:   []string{"webp"},
+			ExtraQualities: []string{"bitonal"},
+			MaxWidth:       &maxWidth,
+		},
+		exampleCapabilities(),
+		nil,
+	)
+
+	mismatch, ok := err.(CapabilityMismatchError)
+	if !ok {
+		t.Fatalf("expected `CapabilityMismatchError` but got: %#+v", err)
+	} else if _e, _a := (FeatureNameList{FeatureNameRotationArbitrary}), mismatch.UnsupportedFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (FeatureNameList{FeatureNameMirroring}), mismatch.UnadvertisedFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"bitonal"}, mismatch.UnsupportedQualities; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 1, len(mismatch.ExceededLimits); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
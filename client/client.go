@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+// InfoAccept is the Accept header used for image information requests. It prefers the JSON-LD media type described by
+// the specification and falls back to plain JSON.
+const InfoAccept = `application/ld+json;profile="` + iiifimageapi.Context + `", application/json;q=0.9`
+
+// ClientOptions contains the properties which affect how a [Client] sends requests.
+type ClientOptions struct {
+	// HTTPClient is used to send requests. If nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+
+	// UserAgent may be set to identify requests to remote servers.
+	UserAgent string
+
+	// Retries is the number of additional attempts after a network error or retryable status (429 or 5xx other than
+	// 501). If negative, requests are not retried. If zero, 3 is used.
+	Retries int
+
+	// RetryBackoff is the delay before the first retry, doubling for each subsequent retry. A Retry-After header takes
+	// precedence. If zero, 250ms is used.
+	RetryBackoff time.Duration
+}
+
+// Client fetches image information and images from remote services.
+type Client struct {
+	httpClient   *http.Client
+	userAgent    string
+	retries      int
+	retryBackoff time.Duration
+}
+
+// NewClient creates a client with the given options.
+func NewClient(opts ClientOptions) *Client {
+	c := &Client{
+		httpClient:   opts.HTTPClient,
+		userAgent:    opts.UserAgent,
+		retries:      opts.Retries,
+		retryBackoff: opts.RetryBackoff,
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	if c.retries == 0 {
+		c.retries = 3
+	} else if c.retries < 0 {
+		c.retries = 0
+	}
+
+	if c.retryBackoff == 0 {
+		c.retryBackoff = 250 * time.Millisecond
+	}
+
+	return c
+}
+
+// StatusError is returned for unsuccessful responses.
+type StatusError struct {
+	URL        string
+	StatusCode int
+
+	// Message is the (truncated) response body, which typically describes the error.
+	Message string
+}
+
+func (e StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: unexpected status: %d", e.URL, e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s: unexpected status: %d: %s", e.URL, e.StatusCode, e.Message)
+}
+
+// GetService fetches the image information of a service. The url may be the base URI of the image or the URL of its
+// info.json document.
+func (c *Client) GetService(ctx context.Context, url string) (*Service, error) {
+	if !strings.HasSuffix(url, "/info.json") {
+		url = strings.TrimSuffix(url, "/") + "/info.json"
+	}
+
+	res, err := c.get(ctx, url, InfoAccept)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		iiifimageapi.ImageInformation
+
+		// Context may also be a list, such as when extension contexts are used.
+		Context json.RawMessage `json:"@context"`
+	}
+
+	err = json.Unmarshal(res.Data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decoding: %v", url, err)
+	} else if !hasContext(doc.Context, iiifimageapi.Context) {
+		return nil, fmt.Errorf("%s: unsupported context: %s", url, doc.Context)
+	} else if doc.ID == "" {
+		return nil, fmt.Errorf("%s: missing id", url)
+	}
+
+	info := doc.ImageInformation
+	info.Context = iiifimageapi.Context
+
+	return newService(c, info)
+}
+
+// hasContext returns true if the JSON-LD context of data, either a string or a list of strings and objects, includes
+// expected.
+func hasContext(data json.RawMessage, expected string) bool {
+	var single string
+
+	if json.Unmarshal(data, &single) == nil {
+		return single == expected
+	}
+
+	var list []json.RawMessage
+
+	if json.Unmarshal(data, &list) != nil {
+		return false
+	}
+
+	for _, item := range list {
+		if json.Unmarshal(item, &single) == nil && single == expected {
+			return true
+		}
+	}
+
+	return false
+}
+
+type response struct {
+	URL         string
+	ContentType string
+	Header      http.Header
+	Data        []byte
+}
+
+func (c *Client) get(ctx context.Context, url, accept string) (response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			delay := c.retryBackoff << (attempt - 1)
+
+			var retryAfter retryAfterError
+			if errors.As(lastErr, &retryAfter) && retryAfter.delay > 0 {
+				delay = retryAfter.delay
+			}
+
+			timer := time.NewTimer(delay)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return response{}, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		res, retryable, err := c.getOnce(ctx, url, accept)
+		if err == nil {
+			return res, nil
+		} else if !retryable || ctx.Err() != nil {
+			return response{}, err
+		}
+
+		lastErr = err
+	}
+
+	return response{}, lastErr
+}
+
+func (c *Client) getOnce(ctx context.Context, url, accept string) (response, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return response{}, false, err
+	}
+
+	req.Header.Set("Accept", accept)
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return response{}, true, err
+	}
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return response{}, true, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		err := StatusError{
+			URL:        url,
+			StatusCode: res.StatusCode,
+			Message:    truncateMessage(data),
+		}
+
+		if res.StatusCode == http.StatusTooManyRequests || (res.StatusCode >= 500 && res.StatusCode != http.StatusNotImplemented) {
+			return response{}, true, retryAfterError{
+				StatusError: err,
+				delay:       parseRetryAfter(res.Header.Get("Retry-After")),
+			}
+		}
+
+		return response{}, false, err
+	}
+
+	return response{
+		URL:         res.Request.URL.String(),
+		ContentType: res.Header.Get("Content-Type"),
+		Header:      res.Header,
+		Data:        data,
+	}, false, nil
+}
+
+// retryAfterError is a retryable StatusError which may include the delay requested by the server.
+type retryAfterError struct {
+	StatusError
+
+	delay time.Duration
+}
+
+func (e retryAfterError) Unwrap() error {
+	return e.StatusError
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	} else if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+
+	return 0
+}
+
+func truncateMessage(data []byte) string {
+	const max = 512
+
+	message := strings.TrimSpace(string(data))
+	if len(message) > max {
+		message = message[:max] + "..."
+	}
+
+	return message
+}
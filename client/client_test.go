@@ -0,0 +1,292 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+// newExampleServer serves a static export of a 100x60 image from the library's own handler at /iiif/example.
+func newExampleServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	fsys := fstest.MapFS{}
+
+	var handler http.Handler = http.StripPrefix("/iiif", static.NewHandler(fsys, static.HandlerOptions{}))
+
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	src := image.NewRGBA(image.Rect(0, 0, 100, 60))
+
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 100; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	out := static.NewMemoryOutput()
+
+	_, err := static.Export(context.Background(), src, out, static.ExportOptions{
+		ImageInformation: iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+			ID:      server.URL + "/iiif/example",
+			Profile: iiifimageapi.ComplianceLevel0Name,
+			Width:   100,
+			Height:  60,
+			Sizes: []iiifimageapi.ImageInformationSize{
+				{Width: 50, Height: 30},
+			},
+			Tiles: []iiifimageapi.ImageInformationTile{
+				{Width: 64, ScaleFactors: []uint32{1, 2}},
+			},
+			ExtraFeatures: iiifimageapi.FeatureNameList{iiifimageapi.FeatureNameCors},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	for path, data := range out.Files() {
+		fsys["example/"+path] = &fstest.MapFile{Data: data}
+	}
+
+	return server
+}
+
+func mustParse(t *testing.T, path string) imagerequest.ParsedParams {
+	raw, err := imagerequest.RawParamsFromString(path)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	parsed, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	return parsed
+}
+
+func TestClient_GetService(t *testing.T) {
+	server := newExampleServer(t, nil)
+
+	for _, url := range []string{
+		server.URL + "/iiif/example",
+		server.URL + "/iiif/example/info.json",
+	} {
+		service, err := NewClient(ClientOptions{}).GetService(context.Background(), url)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		} else if _e, _a := iiifimageapi.ComplianceLevel0Name, service.Profile(); _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		} else if _e, _a := uint32(100), service.ImageInformation().Width; _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		} else if !service.Supports(iiifimageapi.FeatureNameCors) {
+			t.Fatal("expected cors to be supported")
+		} else if service.Supports(iiifimageapi.FeatureNameRegionByPx) {
+			t.Fatal("expected regionByPx to not be supported")
+		}
+	}
+}
+
+func TestClient_GetService_NotFound(t *testing.T) {
+	server := newExampleServer(t, nil)
+
+	_, err := NewClient(ClientOptions{}).GetService(context.Background(), server.URL+"/iiif/unknown")
+
+	var statusErr StatusError
+
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected status error but got: %v", err)
+	} else if _e, _a := http.StatusNotFound, statusErr.StatusCode; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestService_Download(t *testing.T) {
+	server := newExampleServer(t, nil)
+
+	service, err := NewClient(ClientOptions{}).GetService(context.Background(), server.URL+"/iiif/example")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	img, err := service.Download(context.Background(), mustParse(t, "full/50,30/0/default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "image/jpeg", img.ContentType; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := server.URL+"/iiif/example/full/50,30/0/default.jpg", img.URL; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := [2]int{50, 30}, [2]int{cfg.Width, cfg.Height}; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestService_Download_InvalidLocally(t *testing.T) {
+	var requests int32
+
+	server := newExampleServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	service, err := NewClient(ClientOptions{}).GetService(context.Background(), server.URL+"/iiif/example")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	_, err = service.Download(context.Background(), mustParse(t, "0,0,10,10/10,10/0/default.jpg"))
+	if err == nil {
+		t.Fatal("expected error but got none")
+	} else if _e, _a := http.StatusNotImplemented, iiifimageapi.HTTPStatusCode(err); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := int32(1), atomic.LoadInt32(&requests); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestService_Download_Retries(t *testing.T) {
+	var failures int32 = 2
+
+	server := newExampleServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/iiif/example/info.json" && atomic.AddInt32(&failures, -1) >= 0 {
+				http.Error(w, "try again", http.StatusServiceUnavailable)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	client := NewClient(ClientOptions{
+		RetryBackoff: time.Millisecond,
+	})
+
+	service, err := client.GetService(context.Background(), server.URL+"/iiif/example")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	_, err = service.Download(context.Background(), mustParse(t, "full/50,30/0/default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	atomic.StoreInt32(&failures, 10)
+
+	_, err = service.Download(context.Background(), mustParse(t, "full/50,30/0/default.jpg"))
+
+	var statusErr StatusError
+
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected status error but got: %v", err)
+	} else if _e, _a := http.StatusServiceUnavailable, statusErr.StatusCode; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := int32(6), atomic.LoadInt32(&failures); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestService_Resolve_AdvertisedTiles(t *testing.T) {
+	server := newExampleServer(t, nil)
+
+	service, err := NewClient(ClientOptions{}).GetService(context.Background(), server.URL+"/iiif/example")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	// level0 does not support regionByPx or sizeByW, but tile requests commonly use `w,` sizes
+	for _, path := range []string{
+		"0,0,64,60/64,/0/default.jpg",
+		"64,0,36,60/36,/0/default.jpg",
+		"full/50,/0/default.jpg",
+	} {
+		resolved, err := service.Resolve(mustParse(t, path))
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", path, err)
+		} else if _e, _a := "color", resolved.Quality(); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", path, _e, _a)
+		}
+	}
+
+	img, err := service.Download(context.Background(), mustParse(t, "64,0,36,60/36,/0/default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := [2]int{36, 60}, [2]int{cfg.Width, cfg.Height}; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// equivalent features do not make other values advertised
+	_, err = service.Resolve(mustParse(t, "0,0,32,32/32,/0/default.jpg"))
+	if _e, _a := http.StatusNotImplemented, iiifimageapi.HTTPStatusCode(err); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestClient_GetService_Context(t *testing.T) {
+	for _, tc := range []struct {
+		context  string
+		expected bool
+	}{
+		{context: `"http://iiif.io/api/image/3/context.json"`, expected: true},
+		{context: `["http://iiif.io/api/extension/navplace/context.json", "http://iiif.io/api/image/3/context.json"]`, expected: true},
+		{context: `["http://iiif.io/api/image/3/context.json", {"example": "https://example.com/ns#"}]`, expected: true},
+		{context: `"http://iiif.io/api/image/2/context.json"`, expected: false},
+		{context: `["http://iiif.io/api/extension/navplace/context.json"]`, expected: false},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"@context": ` + tc.context + `, "id": "https://example.com/iiif/example", "type": "ImageService3", "protocol": "http://iiif.io/api/image", "profile": "level0", "width": 100, "height": 60}`))
+		}))
+
+		service, err := NewClient(ClientOptions{}).GetService(context.Background(), server.URL+"/iiif/example")
+
+		server.Close()
+
+		if !tc.expected {
+			if err == nil {
+				t.Fatalf("%s: expected error but got none", tc.context)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.context, err)
+		} else if _e, _a := iiifimageapi.Context, service.ImageInformation().Context; _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.context, _e, _a)
+		} else if _e, _a := uint32(100), service.ImageInformation().Width; _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.context, _e, _a)
+		}
+	}
+}
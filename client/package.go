@@ -0,0 +1,3 @@
+// client offers functions to consume remote IIIF Image API 3.0 services. Requests are validated locally against the
+// image information of a service before any network call is made.
+package client
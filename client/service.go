@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// Service is a remote image whose image information has been fetched.
+type Service struct {
+	client *Client
+	info   iiifimageapi.ImageInformation
+	level  iiifimageapi.ComplianceLevelSpec
+}
+
+func newService(client *Client, info iiifimageapi.ImageInformation) (*Service, error) {
+	level, ok := iiifimageapi.DefaultComplianceLevels.GetByName(info.Profile)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported profile: %s", info.ID, info.Profile)
+	}
+
+	return &Service{
+		client: client,
+		info:   info,
+		level:  level,
+	}, nil
+}
+
+// ImageInformation returns the image information document of the service.
+func (s *Service) ImageInformation() iiifimageapi.ImageInformation {
+	return s.info
+}
+
+// Profile returns the compliance level of the service.
+func (s *Service) Profile() iiifimageapi.ComplianceLevelName {
+	return s.info.Profile
+}
+
+// Features returns the features of the compliance level and any extra features, sorted by name.
+func (s *Service) Features() iiifimageapi.FeatureNameList {
+	seen := map[iiifimageapi.FeatureName]struct{}{}
+
+	var features iiifimageapi.FeatureNameList
+
+	for _, feature := range append(append(iiifimageapi.FeatureNameList(nil), s.level.BaseFeatures()...), s.info.ExtraFeatures...) {
+		if _, ok := seen[feature]; ok {
+			continue
+		}
+
+		seen[feature] = struct{}{}
+		features = append(features, feature)
+	}
+
+	features.Sort()
+
+	return features
+}
+
+// Supports returns true if feature is part of the compliance level or an extra feature.
+func (s *Service) Supports(feature iiifimageapi.FeatureName) bool {
+	for _, supported := range s.Features() {
+		if supported == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve validates params against the image information of the service, including its supported features and max
+// constraints. Sizes and tiles advertised by the image information are always allowed, even if describing them
+// requires features outside of the profile (e.g. regionByPx for level0 tiles). The quality of the result is "color"
+// when params uses "default" since the actual default quality of a remote service is unknown.
+func (s *Service) Resolve(params imagerequest.ParsedParams) (imagerequest.ResolvedParams, error) {
+	opts := imagerequest.ResolveOptions{
+		ImageInformation: s.info,
+		DefaultQuality:   "color",
+	}
+
+	resolved, err := params.Resolve(opts)
+	if err == nil || !errors.As(err, new(iiifimageapi.FeatureNotSupportedError)) {
+		return resolved, err
+	}
+
+	opts.IgnoreFeatureErrors = true
+
+	advertised, advertisedErr := params.Resolve(opts)
+	if advertisedErr != nil {
+		return imagerequest.ResolvedParams{}, err
+	}
+
+	value := pixelset.Value{
+		Region: advertised.RegionPixels(),
+		Size:   advertised.SizePixels(),
+	}
+
+	if !pixelset.NewImageDomain(s.info).Contains(value) {
+		return imagerequest.ResolvedParams{}, err
+	}
+
+	return advertised, nil
+}
+
+// URL returns the image request URL of params after validating it with [Service.Resolve].
+func (s *Service) URL(params imagerequest.ParsedParams) (string, error) {
+	_, err := s.Resolve(params)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(s.info.ID, "/") + "/" + params.String(), nil
+}
+
+// Image is the response of an image request.
+type Image struct {
+	// URL is the final URL of the image, after any redirects (e.g. to a canonical URL).
+	URL string
+
+	ContentType string
+	Data        []byte
+}
+
+// Download validates params (see [Service.Resolve]) and, if valid, fetches the image with retries.
+func (s *Service) Download(ctx context.Context, params imagerequest.ParsedParams) (Image, error) {
+	url, err := s.URL(params)
+	if err != nil {
+		return Image{}, err
+	}
+
+	accept := "*/*"
+
+	if format, ok := iiifimageapi.DefaultFormats.GetByName(params.Format); ok {
+		accept = format.MediaType
+	}
+
+	res, err := s.client.get(ctx, url, accept)
+	if err != nil {
+		return Image{}, err
+	}
+
+	return Image{
+		URL:         res.URL,
+		ContentType: res.ContentType,
+		Data:        res.Data,
+	}, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+func runExport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: iiif-static export [OPTIONS] SOURCE OUTPUT\n\nOUTPUT is a directory (images are written to OUTPUT/{identifier}) or a .zip, .tar, .tar.gz, or\n.tgz archive (images are written to its root).\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	identifier := fs.String("identifier", "", "image identifier (default: source file name without extension)")
+	baseURL := fs.String("base-url", "", "base URL of the service; the image id is {base-url}/{identifier}")
+	format := fs.String("format", "jpg", "format of rendered images")
+	quality := fs.String("default-quality", "", "quality rendered for default (default: based on the source color model)")
+	tileWidth := fs.Uint("tile-width", 512, "tile width (0 to disable tiles)")
+	tileHeight := fs.Uint("tile-height", 0, "tile height (default: tile width)")
+	sizes := fs.String("sizes", "", "comma-separated widths of full-image sizes to render")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of images rendered in parallel")
+	skipUpToDate := fs.Bool("skip-up-to-date", false, "skip images which are newer than the source")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	} else if fs.NArg() != 2 {
+		fs.Usage()
+
+		return fmt.Errorf("expected 2 arguments but got %d", fs.NArg())
+	}
+
+	sourcePath, outputPath := fs.Arg(0), fs.Arg(1)
+
+	if *identifier == "" {
+		*identifier = strings.TrimSuffix(filepath.Base(sourcePath), filepath.Ext(sourcePath))
+	}
+
+	sourceStat, err := os.Stat(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	src, err := decodeImageFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("decoding source: %v", err)
+	}
+
+	id := *identifier
+	if *baseURL != "" {
+		id = strings.TrimSuffix(*baseURL, "/") + "/" + url.PathEscape(*identifier)
+	}
+
+	info, err := newExportImageInformation(src.Bounds().Size(), exportConfig{
+		id:         id,
+		format:     *format,
+		tileWidth:  uint32(*tileWidth),
+		tileHeight: uint32(*tileHeight),
+		sizes:      *sizes,
+	})
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := openExportOutput(outputPath, *identifier)
+	if err != nil {
+		return err
+	}
+
+	result, err := static.Export(context.Background(), src, out, static.ExportOptions{
+		ImageInformation: info,
+		Format:           *format,
+		DefaultQuality:   *quality,
+		Workers:          *workers,
+		SkipUpToDate:     *skipUpToDate,
+		SourceModTime:    sourceStat.ModTime(),
+	})
+	if err != nil {
+		closeOut()
+
+		return err
+	}
+
+	err = closeOut()
+	if err != nil {
+		return fmt.Errorf("closing output: %v", err)
+	}
+
+	_, err = fmt.Fprintf(stdout, "rendered %d, skipped %d\n", result.Rendered, result.Skipped)
+
+	return err
+}
+
+type exportConfig struct {
+	id         string
+	format     string
+	tileWidth  uint32
+	tileHeight uint32
+	sizes      string
+}
+
+func newExportImageInformation(size image.Point, config exportConfig) (iiifimageapi.ImageInformation, error) {
+	imageSize := [2]uint32{uint32(size.X), uint32(size.Y)}
+
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      config.id,
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   imageSize[0],
+		Height:  imageSize[1],
+	})
+
+	if config.format != "jpg" {
+		info.ExtraFormats = []string{config.format}
+		info.PreferredFormats = []string{config.format}
+	}
+
+	if config.tileWidth > 0 {
+		tileHeight := config.tileHeight
+		if tileHeight == 0 {
+			tileHeight = config.tileWidth
+		}
+
+		tile := iiifimageapi.ImageInformationTile{
+			Width:        config.tileWidth,
+			ScaleFactors: pixelset.GetTileScaleFactors(imageSize, [2]uint32{config.tileWidth, tileHeight}),
+		}
+
+		if tileHeight != config.tileWidth {
+			tile.Height = tileHeight
+		}
+
+		info.Tiles = append(info.Tiles, tile)
+	}
+
+	if config.sizes != "" {
+		resolveOptions := imagerequest.ResolveOptions{
+			ImageInformation:    info,
+			DefaultQuality:      "color",
+			IgnoreFeatureErrors: true,
+		}
+
+		for _, width := range strings.Split(config.sizes, ",") {
+			if _, err := strconv.ParseUint(width, 10, 32); err != nil {
+				return iiifimageapi.ImageInformation{}, fmt.Errorf("parsing sizes: invalid width (%s)", width)
+			}
+
+			parsed, err := imagerequest.ParseRawParams(imagerequest.RawParams{"full", width + ",", "0", "default." + config.format})
+			if err != nil {
+				return iiifimageapi.ImageInformation{}, fmt.Errorf("parsing sizes: %v", err)
+			}
+
+			resolved, err := parsed.Resolve(resolveOptions)
+			if err != nil {
+				return iiifimageapi.ImageInformation{}, fmt.Errorf("resolving size (%s): %v", width, err)
+			}
+
+			info.Sizes = append(info.Sizes, iiifimageapi.ImageInformationSize{
+				Width:  resolved.SizePixels()[0],
+				Height: resolved.SizePixels()[1],
+			})
+		}
+	}
+
+	return info, nil
+}
+
+func decodeImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+
+	return img, err
+}
+
+func openExportOutput(path, identifier string) (static.Output, func() error, error) {
+	var newArchive func(w io.Writer) static.Output
+
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		newArchive = func(w io.Writer) static.Output { return static.NewZipOutput(w) }
+	case strings.HasSuffix(path, ".tar"):
+		newArchive = func(w io.Writer) static.Output { return static.NewTarOutput(w, false) }
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		newArchive = func(w io.Writer) static.Output { return static.NewTarOutput(w, true) }
+	default:
+		out := static.NewDirOutput(filepath.Join(path, url.PathEscape(identifier)))
+
+		return out, out.Close, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := newArchive(f)
+
+	return out, func() error {
+		err := out.Close()
+		if err != nil {
+			f.Close()
+
+			return err
+		}
+
+		return f.Close()
+	}, nil
+}
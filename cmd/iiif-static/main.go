@@ -0,0 +1,51 @@
+// iiif-static works with static trees of IIIF image requests which may be hosted from plain file or object storage.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: iiif-static COMMAND [OPTIONS] ARGS...
+
+Commands:
+  export       render a source image into a static tree or archive
+  verify       compare a static tree against its info.json
+  reconstruct  generate an info.json from an existing static tree
+  migrate      move an Image API 2.x static tree to 3.0 canonical paths
+  serve        serve a directory of static trees over HTTP
+`
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iiif-static: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n\n%s", usage)
+	}
+
+	switch args[0] {
+	case "export":
+		return runExport(args[1:], stdout)
+	case "verify":
+		return runVerify(args[1:], stdout)
+	case "reconstruct":
+		return runReconstruct(args[1:], stdout, stderr)
+	case "migrate":
+		return runMigrate(args[1:], stdout, stderr)
+	case "serve":
+		return runServe(args[1:], stdout)
+	case "help", "-h", "-help", "--help":
+		_, err := io.WriteString(stdout, usage)
+
+		return err
+	}
+
+	return fmt.Errorf("unknown command: %s\n\n%s", args[0], usage)
+}
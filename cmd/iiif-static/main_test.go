@@ -0,0 +1,187 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeExampleSource(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "example.png")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	defer f.Close()
+
+	err = png.Encode(f, image.NewRGBA(image.Rect(0, 0, 300, 200)))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	return path
+}
+
+func TestRun_Export(t *testing.T) {
+	dir := t.TempDir()
+	source := writeExampleSource(t, dir)
+	stdout := &bytes.Buffer{}
+
+	err := run([]string{"export", "-base-url", "https://example.com/iiif", "-tile-width", "128", "-sizes", "150,75", source, filepath.Join(dir, "out")}, stdout, io.Discard)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "rendered 10, skipped 0", stdout.String(); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+
+	infoBytes, err := os.ReadFile(filepath.Join(dir, "out", "example", "info.json"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := `"id": "https://example.com/iiif/example"`, string(infoBytes); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "out", "example", "full", "150,100", "0", "default.jpg")); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	err := run([]string{"unknown"}, &bytes.Buffer{}, io.Discard)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
+
+func TestRun_ExportZip(t *testing.T) {
+	dir := t.TempDir()
+	source := writeExampleSource(t, dir)
+
+	err := run([]string{"export", "-tile-width", "128", source, filepath.Join(dir, "example.zip")}, &bytes.Buffer{}, io.Discard)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(dir, "example.zip"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	defer zr.Close()
+
+	if _, err := zr.Open("manifest.json"); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+}
+
+func TestRun_Verify(t *testing.T) {
+	dir := t.TempDir()
+	source := writeExampleSource(t, dir)
+
+	err := run([]string{"export", "-tile-width", "128", source, filepath.Join(dir, "out")}, &bytes.Buffer{}, io.Discard)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+
+	err = run([]string{"verify", filepath.Join(dir, "out", "example")}, stdout, io.Discard)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := `"ok": true`, stdout.String(); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+
+	err = os.Remove(filepath.Join(dir, "out", "example", "full", "75,50", "0", "default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	stdout.Reset()
+
+	err = run([]string{"verify", filepath.Join(dir, "out", "example")}, stdout, io.Discard)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	} else if _e, _a := `"full/75,50/0/default.jpg"`, stdout.String(); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+}
+
+func TestRun_Reconstruct(t *testing.T) {
+	dir := t.TempDir()
+	source := writeExampleSource(t, dir)
+
+	err := run([]string{"export", "-tile-width", "128", source, filepath.Join(dir, "out")}, &bytes.Buffer{}, io.Discard)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	err = os.Remove(filepath.Join(dir, "out", "example", "info.json"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err = run([]string{"reconstruct", "-id", "https://example.com/iiif/example", filepath.Join(dir, "out", "example")}, stdout, stderr)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "", stderr.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, expected := range []string{`"id": "https://example.com/iiif/example"`, `"width": 300`, `"height": 200`, `"width": 128`} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Fatalf("expected `%v` to contain `%v`", stdout.String(), expected)
+		}
+	}
+}
+
+func TestRun_Migrate(t *testing.T) {
+	dir := t.TempDir()
+	srcDir := filepath.Join(dir, "v2")
+
+	for path, data := range map[string]string{
+		"info.json":               `{"@context":"http://iiif.io/api/image/2/context.json","@id":"https://example.com/iiif/2/example","protocol":"http://iiif.io/api/image","width":300,"height":200,"sizes":[{"width":150,"height":100}],"profile":["http://iiif.io/api/image/2/level0.json"]}`,
+		"full/150,/0/default.jpg": "150",
+	} {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(srcDir, path)), 0o755)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+
+		err = os.WriteFile(filepath.Join(srcDir, path), []byte(data), 0o644)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+
+	err := run([]string{"migrate", "-id", "https://example.com/iiif/3/example", "-redirects", filepath.Join(dir, "redirects.txt"), srcDir, filepath.Join(dir, "v3")}, stdout, io.Discard)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "migrated 1, ignored 0\n", stdout.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "v3", "full", "150,100", "0", "default.jpg")); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	redirects, err := os.ReadFile(filepath.Join(dir, "redirects.txt"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "https://example.com/iiif/2/example/full/150,/0/default.jpg https://example.com/iiif/3/example/full/150,100/0/default.jpg", string(redirects); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+}
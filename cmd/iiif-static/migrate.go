@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+func runMigrate(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: iiif-static migrate [OPTIONS] SOURCE [TARGET]\n\nSOURCE is the directory of an Image API 2.x tree containing info.json. Images are transferred to their\n3.0 canonical paths in TARGET (default: SOURCE) and a 3.0 info.json is written.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	id := fs.String("id", "", "id of the migrated image (default: @id of the 2.x info.json)")
+	mode := fs.String("mode", string(static.MigrationModeCopy), "how images are transferred (copy, move, hardlink)")
+	defaultQuality := fs.String("default-quality", "", "quality which default images represent (default: color)")
+	redirects := fs.String("redirects", "", "path to write a map of `{old} {new}` URLs")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	} else if fs.NArg() != 1 && fs.NArg() != 2 {
+		fs.Usage()
+
+		return fmt.Errorf("expected 1 or 2 arguments but got %d", fs.NArg())
+	}
+
+	srcDir := fs.Arg(0)
+	dstDir := srcDir
+
+	if fs.NArg() == 2 {
+		dstDir = fs.Arg(1)
+	}
+
+	migration, err := static.PlanMigration(os.DirFS(srcDir), static.MigrationOptions{
+		ID:             *id,
+		DefaultQuality: *defaultQuality,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range migration.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+
+	err = static.ApplyMigration(srcDir, dstDir, migration, static.MigrationMode(*mode))
+	if err != nil {
+		return err
+	}
+
+	if *redirects != "" {
+		f, err := os.Create(*redirects)
+		if err != nil {
+			return err
+		}
+
+		err = static.WriteRedirects(f, migration.Redirects())
+		if err != nil {
+			f.Close()
+
+			return err
+		}
+
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(stdout, "migrated %d, ignored %d\n", len(migration.Files), len(migration.Warnings))
+
+	return nil
+}
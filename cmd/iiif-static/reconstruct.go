@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+func runReconstruct(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("reconstruct", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: iiif-static reconstruct [OPTIONS] IMAGE\n\nIMAGE is the directory or .zip archive of existing images. The inferred info.json is written to\nstdout and warnings about unrecognized or inconsistent files are written to stderr.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	id := fs.String("id", "", "id of the image service")
+	width := fs.Uint("width", 0, "width of the full image (default: inferred)")
+	height := fs.Uint("height", 0, "height of the full image (default: inferred)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("expected 1 argument but got %d", fs.NArg())
+	}
+
+	fsys, closeFS, err := openVerifyFS(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	defer closeFS()
+
+	result, err := static.Reconstruct(fsys, static.ReconstructOptions{
+		ID:     *id,
+		Width:  uint32(*width),
+		Height: uint32(*height),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(result.ImageInformation)
+}
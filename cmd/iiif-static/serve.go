@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+func runServe(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: iiif-static serve [OPTIONS] DIR\n\nDIR contains one {identifier} directory per image, as written by the export command.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	listen := fs.String("listen", "localhost:8080", "address to listen on")
+	defaultQuality := fs.String("default-quality", "", "quality which default images represent (default: color)")
+	linkHeaders := fs.Bool("link-headers", false, "send canonical and profile Link headers and advertise them in info.json")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("expected 1 argument but got %d", fs.NArg())
+	}
+
+	handler := static.NewHandler(os.DirFS(fs.Arg(0)), static.HandlerOptions{
+		DefaultQuality: *defaultQuality,
+		LinkHeaders:    *linkHeaders,
+	})
+
+	fmt.Fprintf(stdout, "listening on http://%s/\n", *listen)
+
+	return http.ListenAndServe(*listen, handler)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+func runVerify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: iiif-static verify [OPTIONS] IMAGE\n\nIMAGE is the directory or .zip archive containing info.json. A JSON report is written to stdout\nand the exit code is non-zero if the tree is incomplete.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	format := fs.String("format", "", "format of the expected images (default: first preferred format, or jpg)")
+	ignoreDimensions := fs.Bool("ignore-dimensions", false, "only check that files exist")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("expected 1 argument but got %d", fs.NArg())
+	}
+
+	fsys, closeFS, err := openVerifyFS(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	defer closeFS()
+
+	report, err := static.Verify(fsys, static.VerifyOptions{
+		Format:           *format,
+		IgnoreDimensions: *ignoreDimensions,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	} else if !report.OK {
+		return errors.New("verification failed")
+	}
+
+	return nil
+}
+
+func openVerifyFS(path string) (fs.FS, func() error, error) {
+	if strings.HasSuffix(path, ".zip") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return zr, zr.Close, nil
+	}
+
+	return os.DirFS(path), func() error { return nil }, nil
+}
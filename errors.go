@@ -1,6 +1,10 @@
 package iiifimageapi
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 // FeatureNotSupportedError indicates an input constraint would have required a feature that is not supported.
 type FeatureNotSupportedError FeatureName
@@ -26,3 +30,57 @@ func NewInvalidValueError(s string) InvalidValueError {
 func (e InvalidValueError) Error() string {
 	return e.s
 }
+
+//
+
+// UnauthorizedError indicates the request requires credentials which were not provided. For HTTP runtimes, this
+// translates to an HTTP 401 Unauthorized.
+type UnauthorizedError struct {
+	s string
+}
+
+func NewUnauthorizedError(s string) UnauthorizedError {
+	return UnauthorizedError{
+		s: s,
+	}
+}
+
+func (e UnauthorizedError) Error() string {
+	return e.s
+}
+
+//
+
+// ForbiddenError indicates the request is not permitted for the current principal. For HTTP runtimes, this translates
+// to an HTTP 403 Forbidden.
+type ForbiddenError struct {
+	s string
+}
+
+func NewForbiddenError(s string) ForbiddenError {
+	return ForbiddenError{
+		s: s,
+	}
+}
+
+func (e ForbiddenError) Error() string {
+	return e.s
+}
+
+//
+
+// HTTPStatusCode returns the HTTP status code described by the specification for err. Unrecognized errors translate
+// to an HTTP 500 Internal Server Error.
+func HTTPStatusCode(err error) int {
+	if errors.As(err, new(InvalidValueError)) {
+		return http.StatusBadRequest
+	} else if errors.As(err, new(UnauthorizedError)) {
+		return http.StatusUnauthorized
+	} else if errors.As(err, new(ForbiddenError)) {
+		return http.StatusForbidden
+	} else if errors.As(err, new(FeatureNotSupportedError)) {
+		return http.StatusNotImplemented
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,276 @@
+package iiifimageapi
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Encoder writes an image in a specific format.
+type Encoder interface {
+	Encode(w io.Writer, img image.Image) error
+}
+
+// EncoderFunc adapts a function to the [Encoder] interface.
+type EncoderFunc func(w io.Writer, img image.Image) error
+
+func (f EncoderFunc) Encode(w io.Writer, img image.Image) error {
+	return f(w, img)
+}
+
+// Format describes an image format which may be requested.
+type Format struct {
+	// Name is the value used in image requests (e.g. "jpg").
+	Name string
+
+	// MediaType is used for the Content-Type header (e.g. "image/jpeg").
+	MediaType string
+
+	// Extensions are the file extensions of the format, including the leading dot. The first is preferred.
+	Extensions []string
+
+	// Encoder may be nil if the format cannot be rendered locally.
+	Encoder Encoder
+}
+
+// Extension returns the preferred file extension.
+func (f Format) Extension() string {
+	if len(f.Extensions) > 0 {
+		return f.Extensions[0]
+	}
+
+	return "." + f.Name
+}
+
+// ContentDisposition returns a Content-Disposition header value for an inline file with the given basename (without
+// extension).
+func (f Format) ContentDisposition(basename string) string {
+	return mime.FormatMediaType("inline", map[string]string{
+		"filename": basename + f.Extension(),
+	})
+}
+
+// OfficialFormats is the list of formats defined by the specification. Encoders are configured for the formats which
+// are supported by the standard library.
+//
+// Since those encoders import image/jpeg, image/png, and image/gif, importing this package also registers their
+// decoders with [image.Decode] as a side effect.
+var OfficialFormats = []Format{
+	{
+		Name:       "jpg",
+		MediaType:  "image/jpeg",
+		Extensions: []string{".jpg", ".jpeg"},
+		Encoder: EncoderFunc(func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, nil)
+		}),
+	},
+	{
+		Name:       "tif",
+		MediaType:  "image/tiff",
+		Extensions: []string{".tif", ".tiff"},
+	},
+	{
+		Name:       "png",
+		MediaType:  "image/png",
+		Extensions: []string{".png"},
+		Encoder:    EncoderFunc(png.Encode),
+	},
+	{
+		Name:       "gif",
+		MediaType:  "image/gif",
+		Extensions: []string{".gif"},
+		Encoder: EncoderFunc(func(w io.Writer, img image.Image) error {
+			return gif.Encode(w, img, nil)
+		}),
+	},
+	{
+		Name:       "jp2",
+		MediaType:  "image/jp2",
+		Extensions: []string{".jp2"},
+	},
+	{
+		Name:       "pdf",
+		MediaType:  "application/pdf",
+		Extensions: []string{".pdf"},
+	},
+	{
+		Name:       "webp",
+		MediaType:  "image/webp",
+		Extensions: []string{".webp"},
+	},
+}
+
+// FormatRegistry is a set of known formats. It is safe for concurrent use.
+type FormatRegistry struct {
+	mu      sync.RWMutex
+	formats map[string]Format
+}
+
+// NewFormatRegistry creates a registry with the [OfficialFormats].
+func NewFormatRegistry() *FormatRegistry {
+	r := &FormatRegistry{
+		formats: map[string]Format{},
+	}
+
+	for _, format := range OfficialFormats {
+		r.formats[format.Name] = format
+	}
+
+	return r
+}
+
+// DefaultFormats is a shared registry which starts with the [OfficialFormats].
+var DefaultFormats = NewFormatRegistry()
+
+// Register adds a custom format. An error is returned if a format with the same name is already registered.
+func (r *FormatRegistry) Register(format Format) error {
+	if format.Name == "" {
+		return errors.New("format name must not be empty")
+	} else if format.MediaType == "" {
+		return fmt.Errorf("format (%s): media type must not be empty", format.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, known := r.formats[format.Name]; known {
+		return fmt.Errorf("format (%s) is already registered", format.Name)
+	}
+
+	format.Extensions = append([]string(nil), format.Extensions...)
+	r.formats[format.Name] = format
+
+	return nil
+}
+
+// SetEncoder replaces the encoder of a registered format.
+func (r *FormatRegistry) SetEncoder(name string, encoder Encoder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	format, known := r.formats[name]
+	if !known {
+		return fmt.Errorf("format (%s) is not registered", name)
+	}
+
+	format.Encoder = encoder
+	r.formats[name] = format
+
+	return nil
+}
+
+// GetByName returns the format for a request value (e.g. "jpg").
+func (r *FormatRegistry) GetByName(name string) (Format, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	format, known := r.formats[name]
+
+	return format, known
+}
+
+// GetByMediaType returns the format for a media type (e.g. "image/jpeg"). Media type parameters are ignored.
+func (r *FormatRegistry) GetByMediaType(mediaType string) (Format, bool) {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, name := range r.sortedNames() {
+		if r.formats[name].MediaType == mediaType {
+			return r.formats[name], true
+		}
+	}
+
+	return Format{}, false
+}
+
+// GetByExtension returns the format for a file extension (e.g. ".jpeg"). The leading dot is optional and the
+// comparison is case-insensitive.
+func (r *FormatRegistry) GetByExtension(ext string) (Format, bool) {
+	ext = strings.ToLower(ext)
+
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, name := range r.sortedNames() {
+		for _, candidate := range r.formats[name].Extensions {
+			if strings.ToLower(candidate) == ext {
+				return r.formats[name], true
+			}
+		}
+	}
+
+	return Format{}, false
+}
+
+// Names returns the names of all registered formats, sorted.
+func (r *FormatRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedNames()
+}
+
+// Encode writes img using the encoder of the named format.
+func (r *FormatRegistry) Encode(w io.Writer, name string, img image.Image) error {
+	format, known := r.GetByName(name)
+	if !known {
+		return fmt.Errorf("format (%s) is not registered", name)
+	} else if format.Encoder == nil {
+		return fmt.Errorf("format (%s) has no encoder", name)
+	}
+
+	return format.Encoder.Encode(w, img)
+}
+
+// ValidatePreferredFormats ensures the preferred formats of info are registered and advertised, either as an extra
+// format or by its compliance level. If levels is nil, [DefaultComplianceLevels] will be used.
+func (r *FormatRegistry) ValidatePreferredFormats(info ImageInformation, levels ComplianceLevels) error {
+	if levels == nil {
+		levels = DefaultComplianceLevels
+	}
+
+	spec, ok := levels.GetByName(info.Profile)
+	if !ok {
+		return fmt.Errorf("profile (%s) is not supported", info.Profile)
+	}
+
+	advertised := stringSet(append(append([]string(nil), spec.BaseFormats()...), info.ExtraFormats...))
+
+	for _, name := range info.PreferredFormats {
+		if _, known := r.GetByName(name); !known {
+			return fmt.Errorf("preferred format (%s) is not registered", name)
+		} else if _, ok := advertised[name]; !ok {
+			return fmt.Errorf("preferred format (%s) is not advertised", name)
+		}
+	}
+
+	return nil
+}
+
+func (r *FormatRegistry) sortedNames() []string {
+	var names []string
+
+	for name := range r.formats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
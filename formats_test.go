@@ -0,0 +1,88 @@
+package iiifimageapi
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func TestFormatRegistry_Lookup(t *testing.T) {
+	r := NewFormatRegistry()
+
+	if format, ok := r.GetByExtension("JPEG"); !ok {
+		t.Fatal("expected format but got none")
+	} else if _e, _a := "jpg", format.Name; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if format, ok := r.GetByMediaType("image/tiff; charset=binary"); !ok {
+		t.Fatal("expected format but got none")
+	} else if _e, _a := "tif", format.Name; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if format, ok := r.GetByName("png"); !ok {
+		t.Fatal("expected format but got none")
+	} else if _e, _a := `inline; filename=default.png`, format.ContentDisposition("default"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestFormatRegistry_RegisterEncoder(t *testing.T) {
+	r := NewFormatRegistry()
+
+	err := r.Register(Format{
+		Name:       "avif",
+		MediaType:  "image/avif",
+		Extensions: []string{".avif"},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if err := r.Register(Format{Name: "jpg", MediaType: "image/jpeg"}); err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+
+	if err := r.Encode(io.Discard, "avif", img); err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	err = r.SetEncoder("avif", EncoderFunc(png.Encode))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := r.Encode(buf, "avif", img); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if buf.Len() == 0 {
+		t.Fatal("expected encoded bytes")
+	}
+}
+
+func TestFormatRegistry_ValidatePreferredFormats(t *testing.T) {
+	r := NewFormatRegistry()
+
+	err := r.ValidatePreferredFormats(ImageInformation{
+		Profile:          ComplianceLevel1Name,
+		PreferredFormats: []string{"webp", "jpg"},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	err = r.ValidatePreferredFormats(ImageInformation{
+		Profile:          ComplianceLevel1Name,
+		ExtraFormats:     []string{"webp"},
+		PreferredFormats: []string{"webp", "jpg"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+}
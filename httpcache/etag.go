@@ -0,0 +1,33 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// ImageETag returns a strong entity tag (including quotes) of the image of params. It is derived from the canonical
+// form of params and sourceVersion, which identifies the content of the source image (e.g. a content hash or
+// modification time). Equivalent requests share the same tag, and the tag changes whenever the source does.
+func ImageETag(params imagerequest.ResolvedParams, sourceVersion string) string {
+	return newETag(sourceVersion, params.Canonical().String())
+}
+
+// InfoETag returns a strong entity tag (including quotes) of an encoded info.json document served as mediaType. Since
+// the media type may be negotiated (e.g. with the Accept header), each variant has its own tag.
+func InfoETag(data []byte, mediaType string) string {
+	return newETag(mediaType, string(data))
+}
+
+func newETag(parts ...string) string {
+	h := sha256.New()
+
+	for _, part := range parts {
+		// length-prefixed so parts cannot be ambiguous
+		h.Write([]byte{byte(len(part) >> 24), byte(len(part) >> 16), byte(len(part) >> 8), byte(len(part))})
+		h.Write([]byte(part))
+	}
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
@@ -0,0 +1,49 @@
+package httpcache
+
+import (
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+func mustResolve(t *testing.T, path string) imagerequest.ResolvedParams {
+	raw, err := imagerequest.RawParamsFromString(path)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	parsed, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	resolved, err := parsed.Resolve(imagerequest.ResolveOptions{
+		ImageInformation: iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+			ID:      "https://example.com/iiif/image",
+			Profile: iiifimageapi.ComplianceLevel2Name,
+			Width:   300,
+			Height:  200,
+		}),
+		DefaultQuality: "color",
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	return resolved
+}
+
+func TestImageETag(t *testing.T) {
+	etag := ImageETag(mustResolve(t, "full/150,/0/default.jpg"), "v1")
+
+	if _e, _a := 34, len(etag); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := etag, ImageETag(mustResolve(t, "pct:0,0,100,100/pct:50/0/color.jpg"), "v1"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _a := ImageETag(mustResolve(t, "full/150,/0/default.jpg"), "v2"); etag == _a {
+		t.Fatalf("expected different tag for source version but got: %v", _a)
+	} else if _a := ImageETag(mustResolve(t, "full/150,/0/default.png"), "v1"); etag == _a {
+		t.Fatalf("expected different tag for format but got: %v", _a)
+	}
+}
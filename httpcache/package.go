@@ -0,0 +1,4 @@
+// httpcache offers HTTP caching semantics for image servers, such as strong entity tags of resolved requests and
+// Cache-Control policies for info.json and image responses. Conditional requests are evaluated by
+// [net/http.ServeContent] once the tags are set.
+package httpcache
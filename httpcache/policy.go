@@ -0,0 +1,39 @@
+package httpcache
+
+const (
+	// DefaultInfoCacheControl is the default Cache-Control of info.json responses. It is relatively short since the
+	// document may change with the configuration of the server (e.g. its profile or max constraints) even when the
+	// source image does not.
+	DefaultInfoCacheControl = "public, max-age=3600"
+
+	// DefaultImageCacheControl is the default Cache-Control of image responses. Images are immutable for a given source
+	// version and canonical request, so they may be cached for a year.
+	DefaultImageCacheControl = "public, max-age=31536000, immutable"
+)
+
+// Policy describes the Cache-Control header values of responses. The zero value uses the defaults.
+type Policy struct {
+	// Info is the Cache-Control of info.json responses. If empty, [DefaultInfoCacheControl] is used.
+	Info string
+
+	// Image is the Cache-Control of image responses. If empty, [DefaultImageCacheControl] is used.
+	Image string
+}
+
+// InfoCacheControl returns the Cache-Control of info.json responses.
+func (p Policy) InfoCacheControl() string {
+	if p.Info != "" {
+		return p.Info
+	}
+
+	return DefaultInfoCacheControl
+}
+
+// ImageCacheControl returns the Cache-Control of image responses.
+func (p Policy) ImageCacheControl() string {
+	if p.Image != "" {
+		return p.Image
+	}
+
+	return DefaultImageCacheControl
+}
@@ -0,0 +1,13 @@
+package httpcache
+
+import "testing"
+
+func TestPolicy(t *testing.T) {
+	if _e, _a := DefaultInfoCacheControl, (Policy{}).InfoCacheControl(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := DefaultImageCacheControl, (Policy{}).ImageCacheControl(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "no-cache", (Policy{Image: "no-cache"}).ImageCacheControl(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,209 @@
+package imagerequest
+
+import (
+	"fmt"
+	"math"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// SizePolicy describes how a size is compared to a target box.
+type SizePolicy string
+
+const (
+	// SizePolicyAtLeast prefers the smallest size which covers the target box in both dimensions (e.g. for cropping
+	// to fill).
+	SizePolicyAtLeast SizePolicy = "atLeast"
+
+	// SizePolicyAtMost prefers the largest size which fits within the target box (e.g. to avoid scaling up).
+	SizePolicyAtMost SizePolicy = "atMost"
+
+	// SizePolicyNearest prefers the size closest to fitting within the target box, whether larger or smaller.
+	SizePolicyNearest SizePolicy = "nearest"
+)
+
+type bestSizeCandidate struct {
+	params ParsedParams
+	size   [2]uint32
+
+	// rank orders candidates of an equal fit; advertised sizes are cheapest, then computed sizes, then max
+	rank int
+}
+
+// BestSize finds the full-region request of info which best matches target, a box of [Width, Height], according to
+// policy. Candidates are the advertised sizes (and single tiles), a `!w,h` request if the profile supports it, and
+// `max`. The candidate which fits target most closely is used, and an advertised size is only preferred over an
+// equally sized request since it is typically cached. If nothing satisfies the policy, the nearest supported size is
+// used instead.
+//
+// The result always resolves against info without ignoring features or max constraints. It uses the "default"
+// quality and the first preferred format of info, otherwise "jpg".
+func BestSize(info iiifimageapi.ImageInformation, target [2]uint32, policy SizePolicy) (ParsedParams, error) {
+	if target[0] == 0 || target[1] == 0 {
+		return ParsedParams{}, iiifimageapi.NewInvalidValueError("target: width and height must be greater than 0")
+	}
+
+	switch policy {
+	case SizePolicyAtLeast, SizePolicyAtMost, SizePolicyNearest:
+	default:
+		return ParsedParams{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("policy: value (%s) is not valid", policy))
+	}
+
+	format := "jpg"
+	if len(info.PreferredFormats) > 0 {
+		format = info.PreferredFormats[0]
+	}
+
+	resolveOptions := ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   "color",
+	}
+
+	newParams := func() ParsedParams {
+		return ParsedParams{
+			RegionIsEnum: true,
+			RegionEnum:   "full",
+			Quality:      "default",
+			Format:       format,
+		}
+	}
+
+	var candidates []bestSizeCandidate
+
+	addCandidate := func(params ParsedParams, rank int) {
+		resolved, err := params.Resolve(resolveOptions)
+		if err != nil {
+			return
+		}
+
+		candidates = append(candidates, bestSizeCandidate{
+			params: params,
+			size:   resolved.SizePixels(),
+			rank:   rank,
+		})
+	}
+
+	full := [4]uint32{0, 0, info.Width, info.Height}
+
+	for _, value := range pixelset.NewImageDomain(info).Enumerate() {
+		if value.Region != full || value.Size[0] == 0 || value.Size[1] == 0 {
+			continue
+		}
+
+		size := value.Size
+
+		params := newParams()
+		params.SizePixels = [2]*uint32{&size[0], &size[1]}
+
+		addCandidate(params, 0)
+	}
+
+	{ // computed
+		imageSize := [2]float64{float64(info.Width), float64(info.Height)}
+		containScale := math.Min(float64(target[0])/imageSize[0], float64(target[1])/imageSize[1])
+		coverScale := math.Max(float64(target[0])/imageSize[0], float64(target[1])/imageSize[1])
+
+		// the box confines to target itself when containing; when covering, the other dimension is relaxed so only the
+		// covering dimension is binding
+		for _, box := range [][2]uint32{
+			target,
+			{
+				uint32(math.Max(float64(target[0]), math.Ceil(imageSize[0]*coverScale))),
+				uint32(math.Max(float64(target[1]), math.Ceil(imageSize[1]*coverScale))),
+			},
+		} {
+			box := box
+
+			params := newParams()
+			params.SizeIsConfined = true
+			params.SizeIsUpscaled = containScale > 1 || (box != target && coverScale > 1)
+			params.SizePixels = [2]*uint32{&box[0], &box[1]}
+
+			addCandidate(params, 1)
+		}
+	}
+
+	{ // max
+		params := newParams()
+		params.SizeIsEnum = true
+		params.SizeEnum = "max"
+
+		addCandidate(params, 2)
+	}
+
+	if len(candidates) == 0 {
+		return ParsedParams{}, iiifimageapi.NewInvalidValueError("image information does not support any full size")
+	}
+
+	var best *bestSizeCandidate
+
+	for candidateIdx := range candidates {
+		candidate := &candidates[candidateIdx]
+
+		if !policy.satisfiedBy(candidate.size, target) {
+			continue
+		} else if best == nil || policy.preferred(*candidate, *best, info, target) {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		for candidateIdx := range candidates {
+			candidate := &candidates[candidateIdx]
+
+			if best == nil || SizePolicyNearest.preferred(*candidate, *best, info, target) {
+				best = candidate
+			}
+		}
+	}
+
+	return best.params, nil
+}
+
+func (p SizePolicy) satisfiedBy(size, target [2]uint32) bool {
+	switch p {
+	case SizePolicyAtLeast:
+		return size[0] >= target[0] && size[1] >= target[1]
+	case SizePolicyAtMost:
+		return size[0] <= target[0] && size[1] <= target[1]
+	}
+
+	return true
+}
+
+func (p SizePolicy) preferred(candidate, best bestSizeCandidate, info iiifimageapi.ImageInformation, target [2]uint32) bool {
+	candidateArea := uint64(candidate.size[0]) * uint64(candidate.size[1])
+	bestArea := uint64(best.size[0]) * uint64(best.size[1])
+
+	switch p {
+	case SizePolicyAtLeast, SizePolicyAtMost:
+		if candidateArea == bestArea {
+			return candidate.rank < best.rank
+		} else if p == SizePolicyAtLeast {
+			return candidateArea < bestArea
+		}
+
+		return candidateArea > bestArea
+	}
+
+	candidateDistance := bestSizeDistance(candidate.size, info, target)
+	bestDistance := bestSizeDistance(best.size, info, target)
+
+	if math.Abs(candidateDistance-bestDistance) > 1e-9 {
+		return candidateDistance < bestDistance
+	} else if candidate.rank != best.rank {
+		return candidate.rank < best.rank
+	}
+
+	return candidateArea < bestArea
+}
+
+// bestSizeDistance compares the scale of size to the scale which exactly fits within target, logarithmically so that
+// half and double are equally far.
+func bestSizeDistance(size [2]uint32, info iiifimageapi.ImageInformation, target [2]uint32) float64 {
+	containScale := math.Min(float64(target[0])/float64(info.Width), float64(target[1])/float64(info.Height))
+	scale := math.Max(float64(size[0])/float64(info.Width), float64(size[1])/float64(info.Height))
+
+	return math.Abs(math.Log(scale / containScale))
+}
@@ -0,0 +1,163 @@
+package imagerequest
+
+import (
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+func TestBestSize_Level0(t *testing.T) {
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   3000,
+		Height:  2000,
+		Sizes: []iiifimageapi.ImageInformationSize{
+			{Width: 150, Height: 100},
+			{Width: 300, Height: 200},
+			{Width: 750, Height: 500},
+		},
+		Tiles: []iiifimageapi.ImageInformationTile{
+			{Width: 512, ScaleFactors: []uint32{1, 2, 4, 8}},
+		},
+	})
+
+	for _, tc := range []struct {
+		target   [2]uint32
+		policy   SizePolicy
+		expected string
+	}{
+		{target: [2]uint32{200, 200}, policy: SizePolicyAtLeast, expected: "full/300,200/0/default.jpg"},
+		{target: [2]uint32{200, 200}, policy: SizePolicyAtMost, expected: "full/150,100/0/default.jpg"},
+		// the single tile of scale factor 8 is closer than any size
+		{target: [2]uint32{360, 360}, policy: SizePolicyNearest, expected: "full/375,250/0/default.jpg"},
+		// nothing advertised is large enough
+		{target: [2]uint32{1000, 1000}, policy: SizePolicyAtLeast, expected: "full/max/0/default.jpg"},
+		// nothing advertised is small enough
+		{target: [2]uint32{100, 100}, policy: SizePolicyAtMost, expected: "full/150,100/0/default.jpg"},
+	} {
+		params, err := BestSize(info, tc.target, tc.policy)
+		if err != nil {
+			t.Fatalf("%v %s: expected `nil` but got: %v", tc.target, tc.policy, err)
+		} else if _e, _a := tc.expected, params.String(); _e != _a {
+			t.Fatalf("%v %s: expected `%v` but got: %v", tc.target, tc.policy, _e, _a)
+		}
+
+		_, err = params.Resolve(ResolveOptions{
+			ImageInformation: info,
+			DefaultQuality:   "color",
+		})
+		if err != nil {
+			t.Fatalf("%v %s: expected `nil` but got: %v", tc.target, tc.policy, err)
+		}
+	}
+}
+
+func TestBestSize_Level2(t *testing.T) {
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile:          iiifimageapi.ComplianceLevel2Name,
+		Width:            3000,
+		Height:           2000,
+		PreferredFormats: []string{"webp"},
+		ExtraFormats:     []string{"webp"},
+		ExtraFeatures:    []iiifimageapi.FeatureName{iiifimageapi.FeatureNameSizeUpscaling},
+	})
+
+	for _, tc := range []struct {
+		target   [2]uint32
+		policy   SizePolicy
+		expected string
+		size     [2]uint32
+	}{
+		{target: [2]uint32{200, 200}, policy: SizePolicyAtMost, expected: "full/!200,200/0/default.webp", size: [2]uint32{200, 133}},
+		{target: [2]uint32{200, 200}, policy: SizePolicyAtLeast, expected: "full/!300,200/0/default.webp", size: [2]uint32{300, 200}},
+		{target: [2]uint32{200, 200}, policy: SizePolicyNearest, expected: "full/!200,200/0/default.webp", size: [2]uint32{200, 133}},
+		{target: [2]uint32{1000, 1000}, policy: SizePolicyAtLeast, expected: "full/!1500,1000/0/default.webp", size: [2]uint32{1500, 1000}},
+		{target: [2]uint32{6000, 6000}, policy: SizePolicyAtMost, expected: "full/^!6000,6000/0/default.webp", size: [2]uint32{6000, 4000}},
+	} {
+		params, err := BestSize(info, tc.target, tc.policy)
+		if err != nil {
+			t.Fatalf("%v %s: expected `nil` but got: %v", tc.target, tc.policy, err)
+		} else if _e, _a := tc.expected, params.String(); _e != _a {
+			t.Fatalf("%v %s: expected `%v` but got: %v", tc.target, tc.policy, _e, _a)
+		}
+
+		resolved, err := params.Resolve(ResolveOptions{
+			ImageInformation: info,
+			DefaultQuality:   "color",
+		})
+		if err != nil {
+			t.Fatalf("%v %s: expected `nil` but got: %v", tc.target, tc.policy, err)
+		} else if _e, _a := tc.size, resolved.SizePixels(); _e != _a {
+			t.Fatalf("%v %s: expected `%v` but got: %v", tc.target, tc.policy, _e, _a)
+		}
+	}
+}
+
+func TestBestSize_Level2Advertised(t *testing.T) {
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile: iiifimageapi.ComplianceLevel2Name,
+		Width:   3000,
+		Height:  2000,
+		Sizes: []iiifimageapi.ImageInformationSize{
+			{Width: 300, Height: 200},
+			{Width: 3000, Height: 2000},
+		},
+		Tiles: []iiifimageapi.ImageInformationTile{
+			{Width: 512, ScaleFactors: []uint32{1, 2, 4, 8}},
+		},
+	})
+
+	for _, tc := range []struct {
+		target   [2]uint32
+		policy   SizePolicy
+		expected string
+	}{
+		// computed sizes fit more closely than the single tile or advertised sizes
+		{target: [2]uint32{1000, 1000}, policy: SizePolicyAtMost, expected: "full/!1000,1000/0/default.jpg"},
+		{target: [2]uint32{1000, 1000}, policy: SizePolicyAtLeast, expected: "full/!1500,1000/0/default.jpg"},
+		// advertised sizes are preferred over an equal computed size
+		{target: [2]uint32{300, 200}, policy: SizePolicyAtLeast, expected: "full/300,200/0/default.jpg"},
+		{target: [2]uint32{300, 300}, policy: SizePolicyAtMost, expected: "full/300,200/0/default.jpg"},
+		{target: [2]uint32{375, 375}, policy: SizePolicyNearest, expected: "full/375,250/0/default.jpg"},
+	} {
+		params, err := BestSize(info, tc.target, tc.policy)
+		if err != nil {
+			t.Fatalf("%v %s: expected `nil` but got: %v", tc.target, tc.policy, err)
+		} else if _e, _a := tc.expected, params.String(); _e != _a {
+			t.Fatalf("%v %s: expected `%v` but got: %v", tc.target, tc.policy, _e, _a)
+		}
+	}
+}
+
+func TestBestSize_MaxConstraints(t *testing.T) {
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile:  iiifimageapi.ComplianceLevel2Name,
+		Width:    3000,
+		Height:   2000,
+		MaxWidth: ptrUint32(1000),
+	})
+
+	params, err := BestSize(info, [2]uint32{2000, 2000}, SizePolicyAtLeast)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "full/max/0/default.jpg", params.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	params, err = BestSize(info, [2]uint32{2000, 500}, SizePolicyAtMost)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "full/!2000,500/0/default.jpg", params.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestBestSize_Invalid(t *testing.T) {
+	info := normativeImageInformation()
+
+	if _, err := BestSize(info, [2]uint32{0, 100}, SizePolicyAtMost); err == nil {
+		t.Fatal("expected error for empty target")
+	} else if _, err := BestSize(info, [2]uint32{100, 100}, SizePolicy("unknown")); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,70 @@
+package imagerequest
+
+import (
+	"fmt"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+// CanonicalLinkHeader returns the value of a `Link` header (RFC 8288) referring to the canonical URI of params, where
+// baseURI is the ID of the image (e.g. `<https://example.com/iiif/image/full/max/0/default.jpg>;rel="canonical"`).
+func CanonicalLinkHeader(baseURI string, params ResolvedParams) string {
+	return linkHeader(strings.TrimSuffix(baseURI, "/")+"/"+params.Canonical().String(), "canonical")
+}
+
+// ProfileLinkHeader returns the value of a `Link` header (RFC 8288) referring to the profile document of spec (e.g.
+// `<http://iiif.io/api/image/3/level2.json>;rel="profile"`).
+func ProfileLinkHeader(spec iiifimageapi.ComplianceLevelSpec) string {
+	return linkHeader(spec.ProfileDocument(), "profile")
+}
+
+// LinkHeaders returns the `Link` header values of the canonicalLinkHeader and profileLinkHeader features which are
+// supported by spec or extraFeatures, in that order. Each value should be added as a separate header.
+func LinkHeaders(baseURI string, params ResolvedParams, spec iiifimageapi.ComplianceLevelSpec, extraFeatures iiifimageapi.FeatureNameList) []string {
+	features := featureNameMap(append(append(iiifimageapi.FeatureNameList(nil), spec.BaseFeatures()...), extraFeatures...))
+
+	var values []string
+
+	if _, ok := features[iiifimageapi.FeatureNameCanonicalLinkHeader]; ok {
+		values = append(values, CanonicalLinkHeader(baseURI, params))
+	}
+
+	if _, ok := features[iiifimageapi.FeatureNameProfileLinkHeader]; ok && spec.ProfileDocument() != "" {
+		values = append(values, ProfileLinkHeader(spec))
+	}
+
+	return values
+}
+
+func linkHeader(target, rel string) string {
+	return fmt.Sprintf(`<%s>;rel="%s"`, escapeLinkTarget(target), rel)
+}
+
+// escapeLinkTarget percent-encodes the bytes of target which are not allowed in a URI reference, such as spaces, `>`,
+// and non-ASCII characters. Existing percent-encoding is kept.
+func escapeLinkTarget(target string) string {
+	b := &strings.Builder{}
+
+	for i := 0; i < len(target); i++ {
+		c := target[i]
+
+		if isLinkTargetByte(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func isLinkTargetByte(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+
+	// unreserved, reserved (RFC 3986), and percent
+	return strings.IndexByte("-._~:/?#[]@!$&'()*+,;=%", c) >= 0
+}
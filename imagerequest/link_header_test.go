@@ -0,0 +1,56 @@
+package imagerequest
+
+import (
+	"reflect"
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+func TestLinkHeaders(t *testing.T) {
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      "https://example.com/iiif/an image>/",
+		Profile: iiifimageapi.ComplianceLevel2Name,
+		Width:   300,
+		Height:  200,
+	})
+
+	raw, err := RawParamsFromString("pct:0,0,50,50/75,/0/default.jpg")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	parsed, err := ParseRawParams(raw)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	resolved, err := parsed.Resolve(ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   "color",
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	spec, _ := iiifimageapi.DefaultComplianceLevels.GetByName(info.Profile)
+
+	if _e, _a := `<https://example.com/iiif/an%20image%3E/0,0,150,100/75,50/0/default.jpg>;rel="canonical"`, CanonicalLinkHeader(info.ID, resolved); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := `<http://iiif.io/api/image/3/level2.json>;rel="profile"`, ProfileLinkHeader(spec); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _a := LinkHeaders(info.ID, resolved, spec, nil); len(_a) != 0 {
+		t.Fatalf("expected no values but got: %v", _a)
+	}
+
+	_e := []string{
+		`<https://example.com/iiif/an%20image%3E/0,0,150,100/75,50/0/default.jpg>;rel="canonical"`,
+		`<http://iiif.io/api/image/3/level2.json>;rel="profile"`,
+	}
+
+	if _a := LinkHeaders(info.ID, resolved, spec, iiifimageapi.FeatureNameList{iiifimageapi.FeatureNameProfileLinkHeader, iiifimageapi.FeatureNameCanonicalLinkHeader}); !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
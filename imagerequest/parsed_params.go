@@ -107,18 +107,18 @@ func (p ParsedParams) RegionString() string {
 func (p ParsedParams) SizeString() string {
 	var prefix string
 
-	if p.SizeIsConfined {
-		prefix += "!"
-	}
-
 	if p.SizeIsUpscaled {
 		prefix += "^"
 	}
 
+	if p.SizeIsConfined {
+		prefix += "!"
+	}
+
 	if p.SizeIsEnum {
 		return prefix + p.SizeEnum
 	} else if p.SizeIsPercent {
-		return prefix + strconv.FormatFloat(float64(p.SizePercent), 'f', -1, 64)
+		return prefix + "pct:" + strconv.FormatFloat(float64(p.SizePercent), 'f', -1, 64)
 	}
 
 	var pixelW, pixelH string
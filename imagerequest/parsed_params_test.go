@@ -0,0 +1,30 @@
+package imagerequest
+
+import "testing"
+
+func TestParsedParams_String(t *testing.T) {
+	for _, path := range []string{
+		"full/max/0/default.jpg",
+		"square/^max/90/gray.png",
+		"pct:10,20,30.5,40/pct:50/0/default.jpg",
+		"0,0,100,100/^pct:150/0/default.jpg",
+		"0,0,100,100/150,/0/default.jpg",
+		"0,0,100,100/,150/0/default.jpg",
+		"0,0,100,100/150,100/0/default.jpg",
+		"0,0,100,100/^150,100/0/default.jpg",
+		"0,0,100,100/!150,100/0/default.jpg",
+		"0,0,100,100/^!150,100/0/default.jpg",
+	} {
+		raw, err := RawParamsFromString(path)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", path, err)
+		}
+
+		parsed, err := ParseRawParams(raw)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", path, err)
+		} else if _e, _a := path, parsed.String(); _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+	}
+}
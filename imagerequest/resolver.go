@@ -1,6 +1,7 @@
 package imagerequest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -27,6 +28,16 @@ type ResolveOptions struct {
 	// IgnoreFeatureErrors may be set to true to ignore max width/height/area constraints (according to ImageInformation).
 	// Typically this should only be used when a caller is acting in an administrative role.
 	IgnoreMaxConstraints bool
+
+	// Policy may be set to restrict access according to the principal of the request (e.g. capped sizes or limited
+	// qualities for anonymous users). Errors from the policy are returned as-is, and parameters which are not allowed
+	// by its restrictions result in an [iiifimageapi.ForbiddenError]. Its max constraints are still subject to
+	// IgnoreMaxConstraints.
+	Policy iiifimageapi.Policy
+
+	// PolicyContext is passed to Policy and typically carries an [iiifimageapi.AccessPrincipal]. If nil,
+	// [context.Background] will be used.
+	PolicyContext context.Context
 }
 
 func (r ResolveOptions) getComplianceLevels() iiifimageapi.ComplianceLevels {
@@ -37,6 +48,14 @@ func (r ResolveOptions) getComplianceLevels() iiifimageapi.ComplianceLevels {
 	return iiifimageapi.DefaultComplianceLevels
 }
 
+func (r ResolveOptions) getPolicyContext() context.Context {
+	if r.PolicyContext != nil {
+		return r.PolicyContext
+	}
+
+	return context.Background()
+}
+
 // Resolve validates all properties to ensure they are supported by the given [iiifimageapi.ImageInformation] and converts any
 // relative values to absolute pixel values. The result should be usable by an image processor with no additional
 // calculations.
@@ -49,6 +68,24 @@ func (p ParsedParams) Resolve(opts ResolveOptions) (ResolvedParams, error) {
 		return ResolvedParams{}, errors.New("invalid options: default quality must not be empty")
 	}
 
+	var restrictions iiifimageapi.AccessRestrictions
+
+	if opts.Policy != nil {
+		var err error
+
+		restrictions, err = opts.Policy.Restrictions(opts.getPolicyContext(), opts.ImageInformation)
+		if err != nil {
+			return ResolvedParams{}, err
+		}
+
+		// continue with the restricted view so max constraints and canonical values are consistent with it
+		opts.ImageInformation = restrictions.Apply(opts.ImageInformation)
+
+		if restrictions.DefaultQuality != "" {
+			opts.DefaultQuality = restrictions.DefaultQuality
+		}
+	}
+
 	cl, ok := opts.getComplianceLevels().GetByName(opts.ImageInformation.Profile)
 	if !ok {
 		return ResolvedParams{}, fmt.Errorf("invalid options: image profile (%s) is not supported", opts.ImageInformation.Profile)
@@ -59,7 +96,9 @@ func (p ParsedParams) Resolve(opts ResolveOptions) (ResolvedParams, error) {
 
 		resolved.format = p.Format
 
-		if _, ok := supportedFormats[resolved.format]; !ok {
+		if !restrictions.AllowsFormat(resolved.format) {
+			return ResolvedParams{}, iiifimageapi.NewForbiddenError(fmt.Sprintf("format: value (%s) is not permitted", resolved.format))
+		} else if _, ok := supportedFormats[resolved.format]; !ok {
 			return ResolvedParams{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("format: value (%s) is not supported", resolved.format))
 		}
 	}
@@ -73,7 +112,9 @@ func (p ParsedParams) Resolve(opts ResolveOptions) (ResolvedParams, error) {
 			resolved.quality = opts.DefaultQuality
 		}
 
-		if resolved.quality == "color" || resolved.quality == "gray" {
+		if !restrictions.AllowsQuality(resolved.quality) {
+			return ResolvedParams{}, iiifimageapi.NewForbiddenError(fmt.Sprintf("quality: value (%s) is not permitted", resolved.quality))
+		} else if resolved.quality == "color" || resolved.quality == "gray" {
 			// always supported even if unlisted
 		} else if _, ok := supportedQualities[resolved.quality]; !ok {
 			return ResolvedParams{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("quality: value (%s) is not supported", resolved.quality))
@@ -1,6 +1,7 @@
 package imagerequest
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -343,3 +344,120 @@ func TestParsedParams_Resolve_ValidatorConfinedSizeRequireUpscale(t *testing.T)
 		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
 	}
 }
+
+// policy
+
+func tieredPolicy() iiifimageapi.Policy {
+	return iiifimageapi.PolicyFunc(func(ctx context.Context, info iiifimageapi.ImageInformation) (iiifimageapi.AccessRestrictions, error) {
+		principal, _ := iiifimageapi.AccessPrincipalFromContext(ctx)
+
+		switch principal.Tier {
+		case "staff":
+			return iiifimageapi.AccessRestrictions{}, nil
+		case "public":
+			return iiifimageapi.AccessRestrictions{
+				MaxWidth:         ptrUint32(150),
+				AllowedQualities: []string{"gray"},
+				ForbiddenFormats: []string{"png"},
+				DefaultQuality:   "gray",
+			}, nil
+		}
+
+		return iiifimageapi.AccessRestrictions{}, iiifimageapi.NewUnauthorizedError("login required")
+	})
+}
+
+func TestParsedParams_Resolve_PolicyUnrestricted(t *testing.T) {
+	resolved, err := mustParseImageRequestParams([4]string{"full", "max", "0", "default.png"}).Resolve(
+		ResolveOptions{
+			ImageInformation: normativeImageInformation(),
+			DefaultQuality:   "color",
+			Policy:           tieredPolicy(),
+			PolicyContext:    iiifimageapi.ContextWithAccessPrincipal(context.Background(), iiifimageapi.AccessPrincipal{Tier: "staff"}),
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := [2]uint32{300, 200}, resolved.SizePixels(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "color", resolved.Quality(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestParsedParams_Resolve_PolicyRestricted(t *testing.T) {
+	resolved, err := mustParseImageRequestParams([4]string{"full", "max", "0", "default.jpg"}).Resolve(
+		ResolveOptions{
+			ImageInformation: normativeImageInformation(),
+			DefaultQuality:   "color",
+			Policy:           tieredPolicy(),
+			PolicyContext:    iiifimageapi.ContextWithAccessPrincipal(context.Background(), iiifimageapi.AccessPrincipal{Tier: "public"}),
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := [2]uint32{150, 100}, resolved.SizePixels(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "gray", resolved.Quality(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "full/150,100/0/default.jpg", resolved.Canonical().String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestParsedParams_Resolve_PolicyRestrictedSize(t *testing.T) {
+	_, err := mustParseImageRequestParams([4]string{"full", "300,", "0", "gray.jpg"}).Resolve(
+		ResolveOptions{
+			ImageInformation: normativeImageInformation(),
+			DefaultQuality:   "color",
+			Policy:           tieredPolicy(),
+			PolicyContext:    iiifimageapi.ContextWithAccessPrincipal(context.Background(), iiifimageapi.AccessPrincipal{Tier: "public"}),
+		},
+	)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	} else if _e, _a := "exceeds max width", err.Error(); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+}
+
+func TestParsedParams_Resolve_PolicyForbiddenQuality(t *testing.T) {
+	_, err := mustParseImageRequestParams([4]string{"full", "max", "0", "color.jpg"}).Resolve(
+		ResolveOptions{
+			ImageInformation: normativeImageInformation(),
+			DefaultQuality:   "color",
+			Policy:           tieredPolicy(),
+			PolicyContext:    iiifimageapi.ContextWithAccessPrincipal(context.Background(), iiifimageapi.AccessPrincipal{Tier: "public"}),
+		},
+	)
+	if _, ok := err.(iiifimageapi.ForbiddenError); !ok {
+		t.Fatalf("expected `ForbiddenError` but got: %#+v", err)
+	}
+}
+
+func TestParsedParams_Resolve_PolicyForbiddenFormat(t *testing.T) {
+	_, err := mustParseImageRequestParams([4]string{"full", "max", "0", "gray.png"}).Resolve(
+		ResolveOptions{
+			ImageInformation: normativeImageInformation(),
+			DefaultQuality:   "color",
+			Policy:           tieredPolicy(),
+			PolicyContext:    iiifimageapi.ContextWithAccessPrincipal(context.Background(), iiifimageapi.AccessPrincipal{Tier: "public"}),
+		},
+	)
+	if _, ok := err.(iiifimageapi.ForbiddenError); !ok {
+		t.Fatalf("expected `ForbiddenError` but got: %#+v", err)
+	}
+}
+
+func TestParsedParams_Resolve_PolicyUnauthorized(t *testing.T) {
+	_, err := mustParseImageRequestParams([4]string{"full", "max", "0", "default.jpg"}).Resolve(
+		ResolveOptions{
+			ImageInformation: normativeImageInformation(),
+			DefaultQuality:   "color",
+			Policy:           tieredPolicy(),
+		},
+	)
+	if _, ok := err.(iiifimageapi.UnauthorizedError); !ok {
+		t.Fatalf("expected `UnauthorizedError` but got: %#+v", err)
+	}
+}
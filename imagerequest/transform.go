@@ -0,0 +1,141 @@
+package imagerequest
+
+import "math"
+
+// The transforms below use continuous coordinates where (0, 0) is the top-left edge of the first pixel, so a pixel
+// center is at (x+0.5, y+0.5). The output is the image after the region is cropped, scaled to the size, mirrored, and
+// then rotated clockwise within its bounding box, which is the order defined by the specification.
+
+// OutputSize returns the dimensions of the rendered image, which is the bounding box of the size after rotation. For
+// arbitrary angles it is rounded to whole pixels.
+func (p ResolvedParams) OutputSize() [2]uint32 {
+	w, h := float64(p.sizePixels[0]), float64(p.sizePixels[1])
+	sin, cos := rotationSinCos(p.rotationAmount)
+	sin, cos = math.Abs(sin), math.Abs(cos)
+
+	return [2]uint32{
+		uint32(math.Round(w*cos + h*sin)),
+		uint32(math.Round(w*sin + h*cos)),
+	}
+}
+
+// ToOutput maps a point of the full image to the rendered image. Points outside of the region map outside of the
+// rendered image.
+func (p ResolvedParams) ToOutput(x, y float64) (float64, float64) {
+	size := [2]float64{float64(p.sizePixels[0]), float64(p.sizePixels[1])}
+
+	// crop and scale
+	x = (x - float64(p.regionPixels[0])) * size[0] / float64(p.regionPixels[2])
+	y = (y - float64(p.regionPixels[1])) * size[1] / float64(p.regionPixels[3])
+
+	if p.rotationIsMirrored {
+		x = size[0] - x
+	}
+
+	if p.rotationAmount == 0 {
+		return x, y
+	}
+
+	output := p.OutputSize()
+	sin, cos := rotationSinCos(p.rotationAmount)
+
+	dx, dy := x-size[0]/2, y-size[1]/2
+
+	return dx*cos - dy*sin + float64(output[0])/2, dx*sin + dy*cos + float64(output[1])/2
+}
+
+// ToSource maps a point of the rendered image to the full image. It is the inverse of [ResolvedParams.ToOutput].
+func (p ResolvedParams) ToSource(x, y float64) (float64, float64) {
+	size := [2]float64{float64(p.sizePixels[0]), float64(p.sizePixels[1])}
+
+	if p.rotationAmount != 0 {
+		output := p.OutputSize()
+		sin, cos := rotationSinCos(p.rotationAmount)
+
+		dx, dy := x-float64(output[0])/2, y-float64(output[1])/2
+
+		x, y = dx*cos+dy*sin+size[0]/2, -dx*sin+dy*cos+size[1]/2
+	}
+
+	if p.rotationIsMirrored {
+		x = size[0] - x
+	}
+
+	// unscale and uncrop
+	return x*float64(p.regionPixels[2])/size[0] + float64(p.regionPixels[0]),
+		y*float64(p.regionPixels[3])/size[1] + float64(p.regionPixels[1])
+}
+
+// ToOutputRect maps a rectangle of [X, Y, Width, Height] in the full image to its bounding box in the rendered image.
+func (p ResolvedParams) ToOutputRect(rect [4]float64) [4]float64 {
+	return polygonBounds(p.ToOutputPolygon(rectPolygon(rect)))
+}
+
+// ToSourceRect maps a rectangle of [X, Y, Width, Height] in the rendered image to its bounding box in the full image.
+func (p ResolvedParams) ToSourceRect(rect [4]float64) [4]float64 {
+	return polygonBounds(p.ToSourcePolygon(rectPolygon(rect)))
+}
+
+// ToOutputPolygon maps each point of a polygon in the full image to the rendered image. Unlike rectangles, the shape
+// is preserved for arbitrary angles.
+func (p ResolvedParams) ToOutputPolygon(points [][2]float64) [][2]float64 {
+	out := make([][2]float64, len(points))
+
+	for idx, point := range points {
+		out[idx][0], out[idx][1] = p.ToOutput(point[0], point[1])
+	}
+
+	return out
+}
+
+// ToSourcePolygon maps each point of a polygon in the rendered image to the full image.
+func (p ResolvedParams) ToSourcePolygon(points [][2]float64) [][2]float64 {
+	out := make([][2]float64, len(points))
+
+	for idx, point := range points {
+		out[idx][0], out[idx][1] = p.ToSource(point[0], point[1])
+	}
+
+	return out
+}
+
+// rotationSinCos is exact for multiples of 90 to avoid rounding errors in the common cases.
+func rotationSinCos(degrees float32) (float64, float64) {
+	switch degrees {
+	case 0:
+		return 0, 1
+	case 90:
+		return 1, 0
+	case 180:
+		return 0, -1
+	case 270:
+		return -1, 0
+	}
+
+	return math.Sincos(float64(degrees) * math.Pi / 180)
+}
+
+func rectPolygon(rect [4]float64) [][2]float64 {
+	return [][2]float64{
+		{rect[0], rect[1]},
+		{rect[0] + rect[2], rect[1]},
+		{rect[0] + rect[2], rect[1] + rect[3]},
+		{rect[0], rect[1] + rect[3]},
+	}
+}
+
+func polygonBounds(points [][2]float64) [4]float64 {
+	if len(points) == 0 {
+		return [4]float64{}
+	}
+
+	minX, minY := points[0][0], points[0][1]
+	maxX, maxY := minX, minY
+
+	for _, point := range points[1:] {
+		minX, maxX = math.Min(minX, point[0]), math.Max(maxX, point[0])
+		minY, maxY = math.Min(minY, point[1]), math.Max(maxY, point[1])
+	}
+
+	return [4]float64{minX, minY, maxX - minX, maxY - minY}
+}
@@ -0,0 +1,104 @@
+package imagerequest
+
+import (
+	"math"
+	"testing"
+)
+
+func mustResolveNormative(path [4]string) ResolvedParams {
+	resolved, err := mustParseImageRequestParams(path).Resolve(ResolveOptions{
+		ImageInformation: normativeImageInformation(),
+		DefaultQuality:   "color",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return resolved
+}
+
+func assertPoint(t *testing.T, expected [2]float64, x, y float64) {
+	t.Helper()
+
+	if math.Abs(expected[0]-x) > 1e-6 || math.Abs(expected[1]-y) > 1e-6 {
+		t.Fatalf("expected `%v` but got: [%v %v]", expected, x, y)
+	}
+}
+
+func TestResolvedParams_ToOutput_MirrorRotate(t *testing.T) {
+	resolved := mustResolveNormative([4]string{"100,50,200,100", "100,50", "!90", "default.jpg"})
+
+	if _e, _a := [2]uint32{50, 100}, resolved.OutputSize(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, tc := range []struct {
+		source [2]float64
+		output [2]float64
+	}{
+		// mirrored to the top-right, then rotated to the bottom-right
+		{source: [2]float64{100, 50}, output: [2]float64{50, 100}},
+		// mirrored to the top-left, then rotated to the top-right
+		{source: [2]float64{300, 50}, output: [2]float64{50, 0}},
+		{source: [2]float64{300, 150}, output: [2]float64{0, 0}},
+		{source: [2]float64{200, 100}, output: [2]float64{25, 50}},
+		// outside of the region
+		{source: [2]float64{0, 50}, output: [2]float64{50, 150}},
+	} {
+		x, y := resolved.ToOutput(tc.source[0], tc.source[1])
+		assertPoint(t, tc.output, x, y)
+
+		x, y = resolved.ToSource(tc.output[0], tc.output[1])
+		assertPoint(t, tc.source, x, y)
+	}
+}
+
+func TestResolvedParams_ToOutput_Rotations(t *testing.T) {
+	for _, tc := range []struct {
+		rotation string
+		output   [2]float64
+	}{
+		{rotation: "0", output: [2]float64{1.5, 0.5}},
+		{rotation: "!0", output: [2]float64{298.5, 0.5}},
+		{rotation: "90", output: [2]float64{199.5, 1.5}},
+		{rotation: "180", output: [2]float64{298.5, 199.5}},
+		{rotation: "270", output: [2]float64{0.5, 298.5}},
+		{rotation: "!90", output: [2]float64{199.5, 298.5}},
+	} {
+		resolved := mustResolveNormative([4]string{"full", "max", tc.rotation, "default.jpg"})
+
+		// the center of the second pixel
+		x, y := resolved.ToOutput(1.5, 0.5)
+		assertPoint(t, tc.output, x, y)
+	}
+}
+
+func TestResolvedParams_Transform_Arbitrary(t *testing.T) {
+	resolved := mustResolveNormative([4]string{"full", "150,", "!22.5", "default.jpg"})
+
+	if _e, _a := [2]uint32{177, 150}, resolved.OutputSize(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// the center is fixed
+	x, y := resolved.ToOutput(150, 100)
+	assertPoint(t, [2]float64{88.5, 75}, x, y)
+
+	polygon := [][2]float64{{10, 20}, {250, 30}, {120, 180}}
+
+	for idx, point := range resolved.ToSourcePolygon(resolved.ToOutputPolygon(polygon)) {
+		assertPoint(t, polygon[idx], point[0], point[1])
+	}
+}
+
+func TestResolvedParams_TransformRect(t *testing.T) {
+	resolved := mustResolveNormative([4]string{"100,50,200,100", "100,50", "!90", "default.jpg"})
+
+	if _e, _a := [4]float64{0, 0, 50, 100}, resolved.ToOutputRect([4]float64{100, 50, 200, 100}); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := [4]float64{100, 50, 200, 100}, resolved.ToSourceRect([4]float64{0, 0, 50, 100}); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := [4]float64{260, 110, 40, 20}, resolved.ToSourceRect([4]float64{10, 0, 10, 20}); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,12 @@
+package uint32ptr
+
+// Min returns the tighter of two optional constraints, where nil is unconstrained.
+func Min(a, b *uint32) *uint32 {
+	if a == nil {
+		return b
+	} else if b == nil || *a <= *b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,3 @@
+// uint32ptr offers helpers for the optional *uint32 constraints of image information (e.g. maxWidth), shared by the
+// packages which combine them.
+package uint32ptr
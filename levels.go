@@ -15,6 +15,22 @@ type ComplianceLevels interface {
 	GetByName(name ComplianceLevelName) (ComplianceLevelSpec, bool)
 }
 
-// DefaultComplianceLevels is a shared set of compliance levels that may be reconfigured. By default it is the set of
-// levels defined in the specification.
-var DefaultComplianceLevels ComplianceLevels = OfficialComplianceLevels
+// NewComplianceLevelSpec creates a spec for a custom compliance level. The lists are copied.
+func NewComplianceLevelSpec(name ComplianceLevelName, profileDocument string, features FeatureNameList, qualities, formats []string) ComplianceLevelSpec {
+	return &complianceLevelSpec{
+		name:            name,
+		profileDocument: profileDocument,
+		baseFeatures:    append(FeatureNameList(nil), features...),
+		baseQualities:   append([]string(nil), qualities...),
+		baseFormats:     append([]string(nil), formats...),
+	}
+}
+
+// DefaultComplianceLevelRegistry is a shared registry which starts with the levels, features, and qualities defined in
+// the specification. Custom levels and extensions should be registered here rather than replacing
+// [DefaultComplianceLevels].
+var DefaultComplianceLevelRegistry = NewComplianceLevelRegistry()
+
+// DefaultComplianceLevels is a shared set of compliance levels that may be reconfigured. By default it is
+// [DefaultComplianceLevelRegistry]. Replacing it is not safe while other goroutines are resolving requests.
+var DefaultComplianceLevels ComplianceLevels = DefaultComplianceLevelRegistry
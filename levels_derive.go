@@ -0,0 +1,138 @@
+package iiifimageapi
+
+import (
+	"errors"
+)
+
+// ProfileCapabilities is the set of features, qualities, and formats actually supported for an image.
+type ProfileCapabilities struct {
+	Features  FeatureNameList
+	Qualities []string
+	Formats   []string
+}
+
+// DerivedProfile is the result of [DeriveProfile] and describes the profile-related fields of [ImageInformation].
+type DerivedProfile struct {
+	Profile        ComplianceLevelName
+	ExtraFeatures  FeatureNameList
+	ExtraQualities []string
+	ExtraFormats   []string
+}
+
+// Apply returns a copy of info with the profile and extra fields replaced.
+func (dp DerivedProfile) Apply(info ImageInformation) ImageInformation {
+	info.Profile = dp.Profile
+	info.ExtraFeatures = dp.ExtraFeatures
+	info.ExtraQualities = dp.ExtraQualities
+	info.ExtraFormats = dp.ExtraFormats
+
+	return info
+}
+
+// DeriveProfile finds the most capable official compliance level which is fully satisfied by caps and lists the
+// remaining capabilities as extras. The "default" quality is always considered supported. An error is returned if not
+// even level0 is satisfied (e.g. jpg is not supported).
+func DeriveProfile(caps ProfileCapabilities) (DerivedProfile, error) {
+	features := featureNameSet(caps.Features)
+	qualities := stringSet(append([]string{"default"}, caps.Qualities...))
+	formats := stringSet(caps.Formats)
+
+	var best ComplianceLevelSpec
+
+	for _, name := range OfficialComplianceLevelNames {
+		spec := OfficialComplianceLevels[name]
+
+		if !containsAllFeatureNames(features, spec.BaseFeatures()) {
+			break
+		} else if !containsAllStrings(qualities, spec.BaseQualities()) {
+			break
+		} else if !containsAllStrings(formats, spec.BaseFormats()) {
+			break
+		}
+
+		best = spec
+	}
+
+	if best == nil {
+		return DerivedProfile{}, errors.New("capabilities do not satisfy any compliance level")
+	}
+
+	dp := DerivedProfile{
+		Profile: best.Name(),
+	}
+
+	{
+		base := featureNameSet(best.BaseFeatures())
+
+		for _, feature := range caps.Features {
+			if _, known := base[feature]; !known {
+				base[feature] = struct{}{}
+				dp.ExtraFeatures = append(dp.ExtraFeatures, feature)
+			}
+		}
+	}
+
+	{
+		base := stringSet(best.BaseQualities())
+
+		for _, quality := range caps.Qualities {
+			if _, known := base[quality]; !known {
+				base[quality] = struct{}{}
+				dp.ExtraQualities = append(dp.ExtraQualities, quality)
+			}
+		}
+	}
+
+	{
+		base := stringSet(best.BaseFormats())
+
+		for _, format := range caps.Formats {
+			if _, known := base[format]; !known {
+				base[format] = struct{}{}
+				dp.ExtraFormats = append(dp.ExtraFormats, format)
+			}
+		}
+	}
+
+	return dp, nil
+}
+
+func featureNameSet(fnl FeatureNameList) map[FeatureName]struct{} {
+	out := map[FeatureName]struct{}{}
+
+	for _, fn := range fnl {
+		out[fn] = struct{}{}
+	}
+
+	return out
+}
+
+func stringSet(sl []string) map[string]struct{} {
+	out := map[string]struct{}{}
+
+	for _, s := range sl {
+		out[s] = struct{}{}
+	}
+
+	return out
+}
+
+func containsAllFeatureNames(set map[FeatureName]struct{}, fnl FeatureNameList) bool {
+	for _, fn := range fnl {
+		if _, ok := set[fn]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsAllStrings(set map[string]struct{}, sl []string) bool {
+	for _, s := range sl {
+		if _, ok := set[s]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,199 @@
+package iiifimageapi
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ComplianceLevelExtension describes the capabilities added to a base level by [ComplianceLevelRegistry.Extend].
+type ComplianceLevelExtension struct {
+	Features  FeatureNameList
+	Qualities []string
+	Formats   []string
+}
+
+// ComplianceLevelRegistry is a set of compliance levels, extension features, and extension qualities. It is safe for
+// concurrent use.
+type ComplianceLevelRegistry struct {
+	mu        sync.RWMutex
+	levels    map[ComplianceLevelName]ComplianceLevelSpec
+	features  map[FeatureName]struct{}
+	qualities map[string]struct{}
+}
+
+var _ ComplianceLevels = &ComplianceLevelRegistry{}
+
+// NewComplianceLevelRegistry creates a registry with the levels, features, and qualities defined in the specification.
+func NewComplianceLevelRegistry() *ComplianceLevelRegistry {
+	r := &ComplianceLevelRegistry{
+		levels:    map[ComplianceLevelName]ComplianceLevelSpec{},
+		features:  map[FeatureName]struct{}{},
+		qualities: map[string]struct{}{},
+	}
+
+	for name, spec := range OfficialComplianceLevels {
+		r.levels[name] = spec
+	}
+
+	for _, feature := range OfficialFeatureNames {
+		r.features[feature] = struct{}{}
+	}
+
+	for _, quality := range OfficialQualities {
+		r.qualities[quality] = struct{}{}
+	}
+
+	return r
+}
+
+func (r *ComplianceLevelRegistry) GetByName(name ComplianceLevelName) (ComplianceLevelSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	spec, ok := r.levels[name]
+
+	return spec, ok
+}
+
+// Register adds a level. An error is returned if a level with the same name is already registered. Features and
+// qualities of the level are registered as well.
+func (r *ComplianceLevelRegistry) Register(spec ComplianceLevelSpec) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, known := r.levels[spec.Name()]; known {
+		return fmt.Errorf("compliance level (%s) is already registered", spec.Name())
+	}
+
+	r.levels[spec.Name()] = spec
+
+	for _, feature := range spec.BaseFeatures() {
+		r.features[feature] = struct{}{}
+	}
+
+	for _, quality := range spec.BaseQualities() {
+		r.qualities[quality] = struct{}{}
+	}
+
+	return nil
+}
+
+// Extend registers a custom level which supports everything of an existing level plus the capabilities of ext.
+func (r *ComplianceLevelRegistry) Extend(base ComplianceLevelName, name ComplianceLevelName, profileDocument string, ext ComplianceLevelExtension) (ComplianceLevelSpec, error) {
+	baseSpec, ok := r.GetByName(base)
+	if !ok {
+		return nil, fmt.Errorf("base compliance level (%s) is not registered", base)
+	}
+
+	spec := NewComplianceLevelSpec(
+		name,
+		profileDocument,
+		mergeFeatureNames(baseSpec.BaseFeatures(), ext.Features),
+		mergeStrings(baseSpec.BaseQualities(), ext.Qualities),
+		mergeStrings(baseSpec.BaseFormats(), ext.Formats),
+	)
+
+	err := r.Register(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// RegisterFeature adds an extension feature which may then be advertised in extraFeatures.
+func (r *ComplianceLevelRegistry) RegisterFeature(feature FeatureName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.features[feature] = struct{}{}
+}
+
+// RegisterQuality adds an extension quality which may then be advertised in extraQualities.
+func (r *ComplianceLevelRegistry) RegisterQuality(quality string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.qualities[quality] = struct{}{}
+}
+
+// IsKnownFeature returns true if the feature is defined by the specification or was registered.
+func (r *ComplianceLevelRegistry) IsKnownFeature(feature FeatureName) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, known := r.features[feature]
+
+	return known
+}
+
+// IsKnownQuality returns true if the quality is defined by the specification or was registered.
+func (r *ComplianceLevelRegistry) IsKnownQuality(quality string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, known := r.qualities[quality]
+
+	return known
+}
+
+// Validate ensures the profile, extra features, and extra qualities of info are known to the registry.
+func (r *ComplianceLevelRegistry) Validate(info ImageInformation) error {
+	if _, ok := r.GetByName(info.Profile); !ok {
+		return fmt.Errorf("profile (%s) is not registered", info.Profile)
+	}
+
+	for _, feature := range info.ExtraFeatures {
+		if !r.IsKnownFeature(feature) {
+			return fmt.Errorf("extra feature (%s) is not registered", feature)
+		}
+	}
+
+	for _, quality := range info.ExtraQualities {
+		if !r.IsKnownQuality(quality) {
+			return fmt.Errorf("extra quality (%s) is not registered", quality)
+		}
+	}
+
+	return nil
+}
+
+func mergeFeatureNames(base, extra FeatureNameList) FeatureNameList {
+	out := append(FeatureNameList(nil), base...)
+	seen := map[FeatureName]struct{}{}
+
+	for _, feature := range base {
+		seen[feature] = struct{}{}
+	}
+
+	for _, feature := range extra {
+		if _, known := seen[feature]; known {
+			continue
+		}
+
+		seen[feature] = struct{}{}
+		out = append(out, feature)
+	}
+
+	return out
+}
+
+func mergeStrings(base, extra []string) []string {
+	out := append([]string(nil), base...)
+	seen := map[string]struct{}{}
+
+	for _, v := range base {
+		seen[v] = struct{}{}
+	}
+
+	for _, v := range extra {
+		if _, known := seen[v]; known {
+			continue
+		}
+
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+
+	return out
+}
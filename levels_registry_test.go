@@ -0,0 +1,135 @@
+package iiifimageapi
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestComplianceLevelRegistry_Extend(t *testing.T) {
+	r := NewComplianceLevelRegistry()
+
+	spec, err := r.Extend(ComplianceLevel1Name, "example-level1-webp", "https://example.com/level1-webp.json", ComplianceLevelExtension{
+		Features: FeatureNameList{FeatureNameMirroring, FeatureNameRegionByPx},
+		Formats:  []string{"webp"},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	found, ok := r.GetByName("example-level1-webp")
+	if !ok {
+		t.Fatal("expected level to be registered")
+	} else if _e, _a := spec, found; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"jpg", "webp"}, found.BaseFormats(); !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 9, len(found.BaseFeatures()); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _, ok := OfficialComplianceLevels.GetByName("example-level1-webp"); ok {
+		t.Fatal("expected official levels to be unchanged")
+	}
+}
+
+func TestComplianceLevelRegistry_ExtendDuplicate(t *testing.T) {
+	_, err := NewComplianceLevelRegistry().Extend(ComplianceLevel0Name, ComplianceLevel2Name, "", ComplianceLevelExtension{})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
+
+func TestComplianceLevelRegistry_Validate(t *testing.T) {
+	r := NewComplianceLevelRegistry()
+	info := ImageInformation{
+		Profile:        ComplianceLevel2Name,
+		ExtraFeatures:  FeatureNameList{FeatureNameMirroring, "x-watermark"},
+		ExtraQualities: []string{"bitonal"},
+	}
+
+	if err := r.Validate(info); err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	r.RegisterFeature("x-watermark")
+
+	if err := r.Validate(info); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+}
+
+func TestComplianceLevelRegistry_Concurrent(t *testing.T) {
+	r := NewComplianceLevelRegistry()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, _ = r.Extend(ComplianceLevel2Name, ComplianceLevelName(fmt.Sprintf("custom%d", i)), "", ComplianceLevelExtension{
+				Qualities: []string{fmt.Sprintf("quality%d", i)},
+			})
+
+			for j := 0; j < 100; j++ {
+				r.GetByName(ComplianceLevel2Name)
+				r.IsKnownQuality("gray")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if !r.IsKnownQuality("quality7") {
+		t.Fatal("expected quality to be registered")
+	}
+}
+
+func TestDeriveProfile_Level2(t *testing.T) {
+	dp, err := DeriveProfile(ProfileCapabilities{
+		Features:  append(FeatureNameList{FeatureNameMirroring}, complianceLevel2Spec.BaseFeatures()...),
+		Qualities: []string{"color", "gray", "bitonal"},
+		Formats:   []string{"png", "jpg", "webp"},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := ComplianceLevel2Name, dp.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (FeatureNameList{FeatureNameMirroring}), dp.ExtraFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"bitonal"}, dp.ExtraQualities; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"webp"}, dp.ExtraFormats; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestDeriveProfile_Level0(t *testing.T) {
+	dp, err := DeriveProfile(ProfileCapabilities{
+		Features:  FeatureNameList{FeatureNameRegionByPx, FeatureNameSizeByW},
+		Qualities: []string{"color"},
+		Formats:   []string{"jpg", "png"},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := ComplianceLevel0Name, dp.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (FeatureNameList{FeatureNameRegionByPx, FeatureNameSizeByW}), dp.ExtraFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"color"}, dp.ExtraQualities; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestDeriveProfile_Unsatisfied(t *testing.T) {
+	_, err := DeriveProfile(ProfileCapabilities{
+		Formats: []string{"png"},
+	})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
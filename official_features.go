@@ -52,3 +52,27 @@ const (
 	// FeatureNameSizeUpscaling means image sizes prefixed with ^ may be requested.
 	FeatureNameSizeUpscaling FeatureName = "sizeUpscaling"
 )
+
+// OfficialFeatureNames is the list of features defined by the specification.
+var OfficialFeatureNames = FeatureNameList{
+	FeatureNameBaseUriRedirect,
+	FeatureNameCanonicalLinkHeader,
+	FeatureNameCors,
+	FeatureNameJsonldMediaType,
+	FeatureNameMirroring,
+	FeatureNameProfileLinkHeader,
+	FeatureNameRegionByPct,
+	FeatureNameRegionByPx,
+	FeatureNameRegionSquare,
+	FeatureNameRotationArbitrary,
+	FeatureNameRotationBy90s,
+	FeatureNameSizeByConfinedWh,
+	FeatureNameSizeByH,
+	FeatureNameSizeByPct,
+	FeatureNameSizeByW,
+	FeatureNameSizeByWh,
+	FeatureNameSizeUpscaling,
+}
+
+// OfficialQualities is the list of qualities defined by the specification.
+var OfficialQualities = []string{"default", "color", "gray", "bitonal"}
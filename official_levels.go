@@ -11,7 +11,7 @@ const (
 	ComplianceLevel2ProfileDocument string              = "http://iiif.io/api/image/3/level2.json"
 )
 
-type officialComplianceLevelSpec struct {
+type complianceLevelSpec struct {
 	name            ComplianceLevelName
 	profileDocument string
 	baseFeatures    FeatureNameList
@@ -19,35 +19,35 @@ type officialComplianceLevelSpec struct {
 	baseFormats     []string
 }
 
-func (cl officialComplianceLevelSpec) Name() ComplianceLevelName {
+func (cl complianceLevelSpec) Name() ComplianceLevelName {
 	return cl.name
 }
 
-func (cl officialComplianceLevelSpec) ProfileDocument() string {
+func (cl complianceLevelSpec) ProfileDocument() string {
 	return cl.profileDocument
 }
 
-func (cl officialComplianceLevelSpec) BaseFeatures() FeatureNameList {
+func (cl complianceLevelSpec) BaseFeatures() FeatureNameList {
 	return cl.baseFeatures
 }
 
-func (cl officialComplianceLevelSpec) BaseQualities() []string {
+func (cl complianceLevelSpec) BaseQualities() []string {
 	return cl.baseQualities
 }
 
-func (cl officialComplianceLevelSpec) BaseFormats() []string {
+func (cl complianceLevelSpec) BaseFormats() []string {
 	return cl.baseFormats
 }
 
 var (
-	complianceLevel0Spec = &officialComplianceLevelSpec{
+	complianceLevel0Spec = &complianceLevelSpec{
 		name:            ComplianceLevel0Name,
 		profileDocument: ComplianceLevel0ProfileDocument,
 		baseQualities:   []string{"default"},
 		baseFormats:     []string{"jpg"},
 	}
 
-	complianceLevel1Spec = &officialComplianceLevelSpec{
+	complianceLevel1Spec = &complianceLevelSpec{
 		name:            ComplianceLevel1Name,
 		profileDocument: ComplianceLevel1ProfileDocument,
 		baseFeatures: FeatureNameList{
@@ -64,7 +64,7 @@ var (
 		baseFormats:   []string{"jpg"},
 	}
 
-	complianceLevel2Spec = &officialComplianceLevelSpec{
+	complianceLevel2Spec = &complianceLevelSpec{
 		name:            ComplianceLevel2Name,
 		profileDocument: ComplianceLevel2ProfileDocument,
 		baseFeatures: FeatureNameList{
@@ -101,3 +101,10 @@ var OfficialComplianceLevels = officialComplianceLevels{
 	ComplianceLevel1Name: complianceLevel1Spec,
 	ComplianceLevel2Name: complianceLevel2Spec,
 }
+
+// OfficialComplianceLevelNames is the names of [OfficialComplianceLevels] ordered from least to most capable.
+var OfficialComplianceLevelNames = []ComplianceLevelName{
+	ComplianceLevel0Name,
+	ComplianceLevel1Name,
+	ComplianceLevel2Name,
+}
@@ -1,5 +1,7 @@
 package pixelset
 
+import "sort"
+
 type ValueDomain interface {
 	Contains(p Value) bool
 	Enumerate() ValueList
@@ -14,6 +16,23 @@ type Value struct {
 
 type ValueList []Value
 
+// Sort sorts the values by region and then size.
+func (vl ValueList) Sort() {
+	sort.Slice(vl, func(i, j int) bool {
+		for k := 0; k < 4; k++ {
+			if vl[i].Region[k] != vl[j].Region[k] {
+				return vl[i].Region[k] < vl[j].Region[k]
+			}
+		}
+
+		if vl[i].Size[0] != vl[j].Size[0] {
+			return vl[i].Size[0] < vl[j].Size[0]
+		}
+
+		return vl[i].Size[1] < vl[j].Size[1]
+	})
+}
+
 func (vl ValueList) toValueMap() valueMap {
 	vm := valueMap{}
 
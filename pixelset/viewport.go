@@ -0,0 +1,199 @@
+package pixelset
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Viewport is the area of an image shown by a viewer. Region is in full image pixels and Size is the number of device
+// pixels it is displayed at.
+type Viewport struct {
+	Region [4]uint32
+	Size   [2]uint32
+}
+
+// ViewportTiles are the tiles needed to display a viewport.
+type ViewportTiles struct {
+	// ScaleFactor is the level used for Visible and Prefetch.
+	ScaleFactor uint32
+
+	// Visible are the tiles which intersect the viewport, ordered by row and then column.
+	Visible ValueList
+
+	// Prefetch are the ring of tiles around Visible, ordered by row and then column.
+	Prefetch ValueList
+
+	// ParentScaleFactor is the next larger scale factor, or 0 if ScaleFactor is the largest.
+	ParentScaleFactor uint32
+
+	// Parent are the tiles of ParentScaleFactor which intersect the viewport. Viewers typically show them while the
+	// visible tiles are loading.
+	Parent ValueList
+}
+
+// All returns the tiles of Visible, Prefetch, and Parent in that order.
+func (vt ViewportTiles) All() ValueList {
+	var vl ValueList
+
+	vl = append(vl, vt.Visible...)
+	vl = append(vl, vt.Prefetch...)
+	vl = append(vl, vt.Parent...)
+
+	return vl
+}
+
+// ViewportTiles selects the tiles a deep-zoom viewer would request for viewport. The largest scale factor whose tiles
+// still have at least one image pixel per device pixel is used, falling back to the smallest scale factor when the
+// viewport is upscaled. If the tile configuration has no scale factors, [GetTileScaleFactors] is used. An error is
+// returned if the tile configuration has no size or no usable scale factors.
+func (d ImageTileDomain) ViewportTiles(viewport Viewport) (ViewportTiles, error) {
+	if d.tileSize[0] == 0 || d.tileSize[1] == 0 {
+		return ViewportTiles{}, errors.New("tile width and height must not be 0")
+	}
+
+	scaleFactors := append([]uint32(nil), d.scaleFactors...)
+	if len(scaleFactors) == 0 {
+		scaleFactors = GetTileScaleFactors(d.imageSize, d.tileSize)
+	}
+
+	if len(scaleFactors) == 0 {
+		return ViewportTiles{}, errors.New("tile scale factors must not be empty")
+	}
+
+	for _, scaleFactor := range scaleFactors {
+		if scaleFactor == 0 {
+			return ViewportTiles{}, errors.New("tile scale factors must not be 0")
+		}
+	}
+
+	if viewport.Size[0] == 0 || viewport.Size[1] == 0 || viewport.Region[2] == 0 || viewport.Region[3] == 0 {
+		return ViewportTiles{}, nil
+	}
+
+	region := clipRegion(viewport.Region, d.imageSize)
+	if region[2] == 0 || region[3] == 0 {
+		return ViewportTiles{}, nil
+	}
+
+	sort.Slice(scaleFactors, func(i, j int) bool {
+		return scaleFactors[i] < scaleFactors[j]
+	})
+
+	limit := math.Min(
+		float64(viewport.Region[2])/float64(viewport.Size[0]),
+		float64(viewport.Region[3])/float64(viewport.Size[1]),
+	)
+
+	scaleFactorIdx := 0
+
+	for idx, scaleFactor := range scaleFactors {
+		if float64(scaleFactor) <= limit+1e-9 {
+			scaleFactorIdx = idx
+		}
+	}
+
+	vt := ViewportTiles{
+		ScaleFactor: scaleFactors[scaleFactorIdx],
+	}
+
+	visible := d.tileRange(vt.ScaleFactor, region)
+	vt.Visible = d.tileValues(vt.ScaleFactor, visible, [4]uint32{})
+
+	ring := visible
+
+	if ring[0] > 0 {
+		ring[0]--
+	}
+
+	if ring[1] > 0 {
+		ring[1]--
+	}
+
+	counts := d.tileCounts(vt.ScaleFactor)
+
+	if ring[2] < counts[0] {
+		ring[2]++
+	}
+
+	if ring[3] < counts[1] {
+		ring[3]++
+	}
+
+	vt.Prefetch = d.tileValues(vt.ScaleFactor, ring, visible)
+
+	if scaleFactorIdx+1 < len(scaleFactors) {
+		vt.ParentScaleFactor = scaleFactors[scaleFactorIdx+1]
+		vt.Parent = d.tileValues(vt.ParentScaleFactor, d.tileRange(vt.ParentScaleFactor, region), [4]uint32{})
+	}
+
+	return vt, nil
+}
+
+// tileCounts returns the number of columns and rows of scaleFactor.
+func (d ImageTileDomain) tileCounts(scaleFactor uint32) [2]uint32 {
+	scaledTileWidth := d.tileSize[0] * scaleFactor
+	scaledTileHeight := d.tileSize[1] * scaleFactor
+
+	return [2]uint32{
+		(d.imageSize[0] + scaledTileWidth - 1) / scaledTileWidth,
+		(d.imageSize[1] + scaledTileHeight - 1) / scaledTileHeight,
+	}
+}
+
+// tileRange returns the columns and rows of scaleFactor which intersect region as [minX, minY, maxX, maxY) bounds.
+func (d ImageTileDomain) tileRange(scaleFactor uint32, region [4]uint32) [4]uint32 {
+	scaledTileWidth := d.tileSize[0] * scaleFactor
+	scaledTileHeight := d.tileSize[1] * scaleFactor
+
+	return [4]uint32{
+		region[0] / scaledTileWidth,
+		region[1] / scaledTileHeight,
+		(region[0]+region[2]-1)/scaledTileWidth + 1,
+		(region[1]+region[3]-1)/scaledTileHeight + 1,
+	}
+}
+
+// tileValues lists the tiles within bounds, except those within exclude, ordered by row and then column.
+func (d ImageTileDomain) tileValues(scaleFactor uint32, bounds [4]uint32, exclude [4]uint32) ValueList {
+	scaledTileWidth := d.tileSize[0] * scaleFactor
+	scaledTileHeight := d.tileSize[1] * scaleFactor
+
+	var vl ValueList
+
+	for tileY := bounds[1]; tileY < bounds[3]; tileY++ {
+		for tileX := bounds[0]; tileX < bounds[2]; tileX++ {
+			if tileX >= exclude[0] && tileX < exclude[2] && tileY >= exclude[1] && tileY < exclude[3] {
+				continue
+			}
+
+			region := clipRegion([4]uint32{tileX * scaledTileWidth, tileY * scaledTileHeight, scaledTileWidth, scaledTileHeight}, d.imageSize)
+
+			vl = append(vl, Value{
+				Region: region,
+				Size: [2]uint32{
+					uint32(math.Ceil(float64(region[2]) / float64(scaleFactor))),
+					uint32(math.Ceil(float64(region[3]) / float64(scaleFactor))),
+				},
+			})
+		}
+	}
+
+	return vl
+}
+
+func clipRegion(region [4]uint32, imageSize [2]uint32) [4]uint32 {
+	if region[0] >= imageSize[0] || region[1] >= imageSize[1] {
+		return [4]uint32{region[0], region[1], 0, 0}
+	}
+
+	if region[0]+region[2] > imageSize[0] {
+		region[2] = imageSize[0] - region[0]
+	}
+
+	if region[1]+region[3] > imageSize[1] {
+		region[3] = imageSize[1] - region[1]
+	}
+
+	return region
+}
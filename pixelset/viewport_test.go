@@ -0,0 +1,115 @@
+package pixelset
+
+import (
+	"reflect"
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+func TestImageTileDomain_ViewportTiles(t *testing.T) {
+	d := NewImageTileDomain([2]uint32{3888, 2592}, iiifimageapi.ImageInformationTile{
+		Width:        512,
+		ScaleFactors: []uint32{1, 2, 4, 8},
+	})
+
+	vt, err := d.ViewportTiles(Viewport{
+		Region: [4]uint32{1024, 1024, 2048, 1024},
+		Size:   [2]uint32{1024, 512},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := uint32(2), vt.ScaleFactor; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (ValueList{
+		{Region: [4]uint32{1024, 1024, 1024, 1024}, Size: [2]uint32{512, 512}},
+		{Region: [4]uint32{2048, 1024, 1024, 1024}, Size: [2]uint32{512, 512}},
+	}), vt.Visible; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 10, len(vt.Prefetch); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (Value{Region: [4]uint32{3072, 2048, 816, 544}, Size: [2]uint32{408, 272}}), vt.Prefetch[9]; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(4), vt.ParentScaleFactor; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (ValueList{
+		{Region: [4]uint32{0, 0, 2048, 2048}, Size: [2]uint32{512, 512}},
+		{Region: [4]uint32{2048, 0, 1840, 2048}, Size: [2]uint32{460, 512}},
+	}), vt.Parent; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, value := range vt.All() {
+		if !d.Contains(value) {
+			t.Fatalf("expected value (%v) to be in domain", value)
+		}
+	}
+}
+
+func TestImageTileDomain_ViewportTiles_Limits(t *testing.T) {
+	d := NewImageTileDomain([2]uint32{3888, 2592}, iiifimageapi.ImageInformationTile{
+		Width: 512,
+	})
+
+	// upscaled uses the most detail
+	vt, err := d.ViewportTiles(Viewport{
+		Region: [4]uint32{0, 0, 100, 100},
+		Size:   [2]uint32{400, 400},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := uint32(1), vt.ScaleFactor; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 1, len(vt.Visible); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 3, len(vt.Prefetch); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// thumbnails use the least detail and have no parent
+	vt, err = d.ViewportTiles(Viewport{
+		Region: [4]uint32{0, 0, 3888, 2592},
+		Size:   [2]uint32{100, 67},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := uint32(8), vt.ScaleFactor; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (ValueList{{Region: [4]uint32{0, 0, 3888, 2592}, Size: [2]uint32{486, 324}}}), vt.Visible; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 0, len(vt.Prefetch)+len(vt.Parent); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// outside of the image
+	vt, err = d.ViewportTiles(Viewport{
+		Region: [4]uint32{4000, 0, 100, 100},
+		Size:   [2]uint32{100, 100},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := 0, len(vt.All()); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestImageTileDomain_ViewportTiles_InvalidTiles(t *testing.T) {
+	for _, tile := range []iiifimageapi.ImageInformationTile{
+		{},
+		{Width: 512, ScaleFactors: []uint32{0, 1}},
+	} {
+		d := NewImageTileDomain([2]uint32{3888, 2592}, tile)
+
+		if _, err := d.ViewportTiles(Viewport{Region: [4]uint32{0, 0, 100, 100}, Size: [2]uint32{100, 100}}); err == nil {
+			t.Fatalf("%v: expected error but got none", tile)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package iiifimageapi
+
+import (
+	"context"
+
+	"github.com/dpb587/go-iiif-image-api-v3/internal/uint32ptr"
+)
+
+// AccessPrincipal describes who is requesting an image, typically derived from an IIIF Auth token or session.
+type AccessPrincipal struct {
+	// ID is an application-defined identifier of the principal. It is empty for anonymous requests.
+	ID string
+
+	// Tier is an application-defined access tier (e.g. "public" or "staff").
+	Tier string
+}
+
+type accessPrincipalContextKey struct{}
+
+// ContextWithAccessPrincipal returns a copy of ctx which carries the principal for a [Policy] to inspect.
+func ContextWithAccessPrincipal(ctx context.Context, principal AccessPrincipal) context.Context {
+	return context.WithValue(ctx, accessPrincipalContextKey{}, principal)
+}
+
+// AccessPrincipalFromContext returns the principal previously added with [ContextWithAccessPrincipal].
+func AccessPrincipalFromContext(ctx context.Context) (AccessPrincipal, bool) {
+	principal, ok := ctx.Value(accessPrincipalContextKey{}).(AccessPrincipal)
+
+	return principal, ok
+}
+
+// AccessRestrictions describe a degraded view of an image. The zero value does not restrict anything.
+type AccessRestrictions struct {
+	// MaxWidth, MaxHeight, and MaxArea may be set to tighten the constraints of the image. They are only used when
+	// smaller than those already configured by the image.
+	MaxWidth  *uint32
+	MaxHeight *uint32
+	MaxArea   *uint32
+
+	// AllowedQualities may be set to limit which qualities may be rendered. If nil, all qualities are allowed.
+	AllowedQualities []string
+
+	// ForbiddenFormats lists formats which may not be rendered.
+	ForbiddenFormats []string
+
+	// DefaultQuality may be set to override the quality which is rendered for "default" (e.g. "gray").
+	DefaultQuality string
+}
+
+// AllowsQuality returns true if the quality may be rendered.
+func (r AccessRestrictions) AllowsQuality(quality string) bool {
+	if r.AllowedQualities == nil {
+		return true
+	}
+
+	for _, allowed := range r.AllowedQualities {
+		if allowed == quality {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsFormat returns true if the format may be rendered.
+func (r AccessRestrictions) AllowsFormat(format string) bool {
+	for _, forbidden := range r.ForbiddenFormats {
+		if forbidden == format {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply returns a copy of info which advertises the restricted view. Max constraints are tightened, sizes exceeding
+// them are removed, and extra qualities and formats which are not allowed are removed. Qualities and formats required
+// by the compliance level cannot be removed from the document, but requests for them are still rejected.
+func (r AccessRestrictions) Apply(info ImageInformation) ImageInformation {
+	info.MaxWidth = uint32ptr.Min(info.MaxWidth, r.MaxWidth)
+	info.MaxHeight = uint32ptr.Min(info.MaxHeight, r.MaxHeight)
+	info.MaxArea = uint32ptr.Min(info.MaxArea, r.MaxArea)
+
+	if info.Sizes != nil {
+		var sizes []ImageInformationSize
+
+		for _, size := range info.Sizes {
+			if info.MaxWidth != nil && size.Width > *info.MaxWidth {
+				continue
+			} else if info.MaxHeight != nil && size.Height > *info.MaxHeight {
+				continue
+			} else if info.MaxArea != nil && uint64(size.Width)*uint64(size.Height) > uint64(*info.MaxArea) {
+				continue
+			}
+
+			sizes = append(sizes, size)
+		}
+
+		info.Sizes = sizes
+	}
+
+	if info.ExtraQualities != nil {
+		var qualities []string
+
+		for _, quality := range info.ExtraQualities {
+			if r.AllowsQuality(quality) {
+				qualities = append(qualities, quality)
+			}
+		}
+
+		info.ExtraQualities = qualities
+	}
+
+	if info.ExtraFormats != nil {
+		var formats []string
+
+		for _, format := range info.ExtraFormats {
+			if r.AllowsFormat(format) {
+				formats = append(formats, format)
+			}
+		}
+
+		info.ExtraFormats = formats
+	}
+
+	if info.PreferredFormats != nil {
+		var formats []string
+
+		for _, format := range info.PreferredFormats {
+			if r.AllowsFormat(format) {
+				formats = append(formats, format)
+			}
+		}
+
+		info.PreferredFormats = formats
+	}
+
+	return info
+}
+
+// Policy decides how an image may be accessed by the principal of a request. Implementations may return an
+// [UnauthorizedError] or [ForbiddenError] to deny access entirely.
+type Policy interface {
+	Restrictions(ctx context.Context, info ImageInformation) (AccessRestrictions, error)
+}
+
+// PolicyFunc adapts a function to the [Policy] interface.
+type PolicyFunc func(ctx context.Context, info ImageInformation) (AccessRestrictions, error)
+
+func (f PolicyFunc) Restrictions(ctx context.Context, info ImageInformation) (AccessRestrictions, error) {
+	return f(ctx, info)
+}
+
+// RestrictImageInformation returns the view of info which should be advertised to the principal of ctx.
+func RestrictImageInformation(ctx context.Context, policy Policy, info ImageInformation) (ImageInformation, error) {
+	restrictions, err := policy.Restrictions(ctx, info)
+	if err != nil {
+		return ImageInformation{}, err
+	}
+
+	return restrictions.Apply(info), nil
+}
@@ -0,0 +1,240 @@
+package presentation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// Canvas is a minimal Presentation 3 canvas painted with a single image.
+type Canvas struct {
+	Context   string           `json:"@context,omitempty"`
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	Width     uint32           `json:"width"`
+	Height    uint32           `json:"height"`
+	Thumbnail []ImageResource  `json:"thumbnail,omitempty"`
+	Items     []AnnotationPage `json:"items"`
+}
+
+// AnnotationPage is a Presentation 3 annotation page.
+type AnnotationPage struct {
+	ID    string       `json:"id"`
+	Type  string       `json:"type"`
+	Items []Annotation `json:"items"`
+}
+
+// Annotation is a Presentation 3 annotation with a single image body.
+type Annotation struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	Motivation string        `json:"motivation"`
+	Body       ImageResource `json:"body"`
+	Target     string        `json:"target"`
+}
+
+// ImageResource is an image content resource, such as an annotation body or thumbnail.
+type ImageResource struct {
+	ID      string         `json:"id"`
+	Type    string         `json:"type"`
+	Format  string         `json:"format,omitempty"`
+	Width   uint32         `json:"width,omitempty"`
+	Height  uint32         `json:"height,omitempty"`
+	Service []ImageService `json:"service,omitempty"`
+}
+
+// ImageService is a reference to an image service.
+type ImageService struct {
+	ID      string                           `json:"id"`
+	Type    string                           `json:"type"`
+	Profile iiifimageapi.ComplianceLevelName `json:"profile"`
+}
+
+// CanvasOptions configures [NewCanvas].
+type CanvasOptions struct {
+	// ID is the ID of the canvas. If empty, "/canvas" is appended to the ID of the image.
+	ID string
+
+	// Format is the format of the painted image. If empty, the first preferred format of the image is used, otherwise
+	// "jpg".
+	Format string
+
+	// ThumbnailWidth is the minimum width of the thumbnail. The smallest advertised size at least this wide is used,
+	// otherwise the largest. If 0, 256 is used.
+	ThumbnailWidth uint32
+
+	// NoContext may be set to true to omit `@context`, such as when the canvas is embedded in a manifest.
+	NoContext bool
+
+	// Formats is used to find the media type of the format. If nil, the default formats are used.
+	Formats *iiifimageapi.FormatRegistry
+}
+
+func (o CanvasOptions) getFormats() *iiifimageapi.FormatRegistry {
+	if o.Formats != nil {
+		return o.Formats
+	}
+
+	return iiifimageapi.DefaultFormats
+}
+
+func (o CanvasOptions) getThumbnailWidth() uint32 {
+	if o.ThumbnailWidth > 0 {
+		return o.ThumbnailWidth
+	}
+
+	return 256
+}
+
+// NewCanvas creates a canvas of the dimensions of info, painted with the `max` size of the image and referencing info
+// as its service. A thumbnail is only included when info advertises sizes. Image URLs are canonical whenever the
+// profile accepts them.
+func NewCanvas(info iiifimageapi.ImageInformation, opts CanvasOptions) (Canvas, error) {
+	if info.ID == "" {
+		return Canvas{}, errors.New("image id must not be empty")
+	} else if info.Width == 0 || info.Height == 0 {
+		return Canvas{}, errors.New("image width and height must not be 0")
+	}
+
+	canvasID := opts.ID
+	if canvasID == "" {
+		canvasID = strings.TrimSuffix(info.ID, "/") + "/canvas"
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = "jpg"
+
+		if len(info.PreferredFormats) > 0 {
+			format = info.PreferredFormats[0]
+		}
+	}
+
+	service := []ImageService{
+		{
+			ID:      info.ID,
+			Type:    ServiceTypeImageService3,
+			Profile: info.Profile,
+		},
+	}
+
+	body, err := newImageResource(info, imagerequest.ParsedParams{
+		RegionIsEnum: true,
+		RegionEnum:   "full",
+		SizeIsEnum:   true,
+		SizeEnum:     "max",
+		Quality:      "default",
+		Format:       format,
+	}, opts)
+	if err != nil {
+		return Canvas{}, fmt.Errorf("body: %v", err)
+	}
+
+	body.Service = service
+
+	canvas := Canvas{
+		ID:     canvasID,
+		Type:   "Canvas",
+		Width:  info.Width,
+		Height: info.Height,
+		Items: []AnnotationPage{
+			{
+				ID:   canvasID + "/page",
+				Type: "AnnotationPage",
+				Items: []Annotation{
+					{
+						ID:         canvasID + "/page/annotation",
+						Type:       "Annotation",
+						Motivation: "painting",
+						Body:       body,
+						Target:     canvasID,
+					},
+				},
+			},
+		},
+	}
+
+	if !opts.NoContext {
+		canvas.Context = Context3
+	}
+
+	if size, ok := thumbnailSize(info.Sizes, opts.getThumbnailWidth()); ok {
+		thumbnail, err := newImageResource(info, imagerequest.ParsedParams{
+			RegionIsEnum: true,
+			RegionEnum:   "full",
+			SizePixels:   [2]*uint32{&size.Width, &size.Height},
+			Quality:      "default",
+			Format:       format,
+		}, opts)
+		if err != nil {
+			return Canvas{}, fmt.Errorf("thumbnail: %v", err)
+		}
+
+		thumbnail.Service = service
+
+		canvas.Thumbnail = []ImageResource{thumbnail}
+	}
+
+	return canvas, nil
+}
+
+func newImageResource(info iiifimageapi.ImageInformation, params imagerequest.ParsedParams, opts CanvasOptions) (ImageResource, error) {
+	formatInfo, ok := opts.getFormats().GetByName(params.Format)
+	if !ok {
+		return ImageResource{}, fmt.Errorf("format %s: not registered", params.Format)
+	}
+
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   "color",
+	}
+
+	resolved, err := params.Resolve(resolveOptions)
+	if err != nil {
+		return ImageResource{}, err
+	}
+
+	// level0 profiles may not accept the canonical form of max (e.g. w,h of an unadvertised size)
+	linked := resolved.Canonical()
+	if _, err := linked.Resolve(resolveOptions); err != nil {
+		linked = params
+	}
+
+	size := resolved.SizePixels()
+
+	return ImageResource{
+		ID:     strings.TrimSuffix(info.ID, "/") + "/" + linked.String(),
+		Type:   "Image",
+		Format: formatInfo.MediaType,
+		Width:  size[0],
+		Height: size[1],
+	}, nil
+}
+
+// thumbnailSize returns the smallest size at least minWidth wide, otherwise the largest size.
+func thumbnailSize(sizes []iiifimageapi.ImageInformationSize, minWidth uint32) (iiifimageapi.ImageInformationSize, bool) {
+	var best *iiifimageapi.ImageInformationSize
+
+	for sizeIdx := range sizes {
+		size := &sizes[sizeIdx]
+
+		if best == nil {
+			best = size
+		} else if best.Width < minWidth {
+			if size.Width > best.Width {
+				best = size
+			}
+		} else if size.Width >= minWidth && size.Width < best.Width {
+			best = size
+		}
+	}
+
+	if best == nil {
+		return iiifimageapi.ImageInformationSize{}, false
+	}
+
+	return *best, true
+}
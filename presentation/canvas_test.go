@@ -0,0 +1,85 @@
+package presentation
+
+import (
+	"encoding/json"
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+func TestNewCanvas(t *testing.T) {
+	canvas, err := NewCanvas(iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      "https://example.com/iiif/image",
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   3000,
+		Height:  2000,
+		Sizes: []iiifimageapi.ImageInformationSize{
+			{Width: 1500, Height: 1000},
+			{Width: 150, Height: 100},
+			{Width: 375, Height: 250},
+		},
+	}), CanvasOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	buf, err := json.Marshal(canvas)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	expected := `{"@context":"http://iiif.io/api/presentation/3/context.json","id":"https://example.com/iiif/image/canvas","type":"Canvas","width":3000,"height":2000,` +
+		`"thumbnail":[{"id":"https://example.com/iiif/image/full/375,250/0/default.jpg","type":"Image","format":"image/jpeg","width":375,"height":250,"service":[{"id":"https://example.com/iiif/image","type":"ImageService3","profile":"level0"}]}],` +
+		`"items":[{"id":"https://example.com/iiif/image/canvas/page","type":"AnnotationPage","items":[{"id":"https://example.com/iiif/image/canvas/page/annotation","type":"Annotation","motivation":"painting",` +
+		`"body":{"id":"https://example.com/iiif/image/full/max/0/default.jpg","type":"Image","format":"image/jpeg","width":3000,"height":2000,"service":[{"id":"https://example.com/iiif/image","type":"ImageService3","profile":"level0"}]},` +
+		`"target":"https://example.com/iiif/image/canvas"}]}]}`
+
+	if _e, _a := expected, string(buf); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// round trip
+	services, err := ExtractImageServices([]byte(`{"type":"Manifest","items":[` + string(buf) + `]}`))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 1, len(services); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/image", services[0].ImageInformation.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestNewCanvas_Options(t *testing.T) {
+	maxWidth := uint32(1000)
+
+	canvas, err := NewCanvas(iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:               "https://example.com/iiif/image/",
+		Profile:          iiifimageapi.ComplianceLevel2Name,
+		Width:            3000,
+		Height:           2000,
+		MaxWidth:         &maxWidth,
+		PreferredFormats: []string{"png"},
+	}), CanvasOptions{
+		ID:        "https://example.com/manifest/canvas/1",
+		NoContext: true,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	body := canvas.Items[0].Items[0].Body
+
+	if _e, _a := "", canvas.Context; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 0, len(canvas.Thumbnail); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/manifest/canvas/1", canvas.Items[0].Items[0].Target; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/image/full/1000,666/0/default.png", body.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "image/png", body.Format; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(3000), canvas.Width; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,438 @@
+package presentation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+const (
+	// Context3 is the JSON-LD context of Presentation API 3.0 documents.
+	Context3 = "http://iiif.io/api/presentation/3/context.json"
+
+	// Context2 is the JSON-LD context of Presentation API 2.x documents.
+	Context2 = "http://iiif.io/api/presentation/2/context.json"
+)
+
+const (
+	ServiceTypeImageService3 = "ImageService3"
+	ServiceTypeImageService2 = "ImageService2"
+)
+
+// CanvasImageService is an image service of a painting annotation body on a canvas.
+type CanvasImageService struct {
+	CanvasID     string
+	CanvasWidth  uint32
+	CanvasHeight uint32
+
+	// BodyID is the ID of the painted image resource, typically an image request of the service.
+	BodyID string
+
+	// ServiceType is either [ServiceTypeImageService3] or [ServiceTypeImageService2].
+	ServiceType string
+
+	// ImageInformation is partially filled from the service reference, with at least the ID and profile. Services
+	// rarely embed their dimensions, so the width and height fall back to those of the body and then the canvas,
+	// which may differ from the actual image. Fetch the info.json of the service for authoritative values.
+	ImageInformation iiifimageapi.ImageInformation
+}
+
+// ExtractImageServices finds the image services painted on each canvas of a Presentation 3 manifest, or a
+// Presentation 2 manifest as a fallback. Services of choices are all included, and services which are not supported
+// image services (e.g. of unknown compliance levels or dimensions) are skipped. Services are returned in the order of
+// canvases.
+func ExtractImageServices(data []byte) ([]CanvasImageService, error) {
+	var doc struct {
+		Context   json.RawMessage `json:"@context"`
+		Type      string          `json:"type"`
+		AtType    string          `json:"@type"`
+		Sequences json.RawMessage `json:"sequences"`
+	}
+
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding manifest: %v", err)
+	}
+
+	contexts := unmarshalStrings(doc.Context)
+
+	if containsString(contexts, Context3) || doc.Type == "Manifest" {
+		return extractImageServices3(data)
+	} else if containsString(contexts, Context2) || doc.AtType == "sc:Manifest" || len(doc.Sequences) > 0 {
+		return extractImageServices2(data)
+	}
+
+	return nil, errors.New("unsupported document (expecting a Presentation 3 or 2 manifest)")
+}
+
+type manifest3 struct {
+	Items []struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		Width  uint32 `json:"width"`
+		Height uint32 `json:"height"`
+		Items  []struct {
+			Items []struct {
+				Motivation json.RawMessage `json:"motivation"`
+				Body       json.RawMessage `json:"body"`
+			} `json:"items"`
+		} `json:"items"`
+	} `json:"items"`
+}
+
+type body3 struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Width   uint32          `json:"width"`
+	Height  uint32          `json:"height"`
+	Service json.RawMessage `json:"service"`
+	Items   json.RawMessage `json:"items"`
+}
+
+func extractImageServices3(data []byte) ([]CanvasImageService, error) {
+	var m manifest3
+
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("decoding manifest: %v", err)
+	}
+
+	var services []CanvasImageService
+
+	for canvasIdx, canvas := range m.Items {
+		if canvas.Type != "" && canvas.Type != "Canvas" {
+			continue
+		}
+
+		for _, page := range canvas.Items {
+			for _, annotation := range page.Items {
+				if !containsString(unmarshalStrings(annotation.Motivation), "painting") {
+					continue
+				}
+
+				bodies, err := unmarshalBodies3(annotation.Body)
+				if err != nil {
+					return nil, fmt.Errorf("canvas[%d]: %v", canvasIdx, err)
+				}
+
+				for _, body := range bodies {
+					refs, err := unmarshalServiceRefs(body.Service)
+					if err != nil {
+						return nil, fmt.Errorf("canvas[%d]: %v", canvasIdx, err)
+					}
+
+					for _, ref := range refs {
+						if service, ok := ref.canvasImageService(canvas.ID, canvas.Width, canvas.Height, body.ID, body.Width, body.Height); ok {
+							services = append(services, service)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return services, nil
+}
+
+// unmarshalBodies3 flattens a body, list of bodies, or Choice into the list of bodies.
+func unmarshalBodies3(data json.RawMessage) ([]body3, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var list []json.RawMessage
+
+	if data[0] == '[' {
+		err := json.Unmarshal(data, &list)
+		if err != nil {
+			return nil, fmt.Errorf("decoding body: %v", err)
+		}
+	} else {
+		list = []json.RawMessage{data}
+	}
+
+	var bodies []body3
+
+	for _, raw := range list {
+		var body body3
+
+		err := json.Unmarshal(raw, &body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding body: %v", err)
+		}
+
+		if body.Type == "Choice" {
+			items, err := unmarshalBodies3(body.Items)
+			if err != nil {
+				return nil, err
+			}
+
+			bodies = append(bodies, items...)
+
+			continue
+		}
+
+		bodies = append(bodies, body)
+	}
+
+	return bodies, nil
+}
+
+type manifest2 struct {
+	Sequences []struct {
+		Canvases []struct {
+			ID     string `json:"@id"`
+			Width  uint32 `json:"width"`
+			Height uint32 `json:"height"`
+			Images []struct {
+				Motivation string    `json:"motivation"`
+				Resource   resource2 `json:"resource"`
+			} `json:"images"`
+		} `json:"canvases"`
+	} `json:"sequences"`
+}
+
+type resource2 struct {
+	ID      string          `json:"@id"`
+	Type    string          `json:"@type"`
+	Width   uint32          `json:"width"`
+	Height  uint32          `json:"height"`
+	Service json.RawMessage `json:"service"`
+	Default *resource2      `json:"default"`
+	Item    []resource2     `json:"item"`
+}
+
+func extractImageServices2(data []byte) ([]CanvasImageService, error) {
+	var m manifest2
+
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("decoding manifest: %v", err)
+	}
+
+	var services []CanvasImageService
+
+	for _, sequence := range m.Sequences {
+		for canvasIdx, canvas := range sequence.Canvases {
+			for _, image := range canvas.Images {
+				if image.Motivation != "" && image.Motivation != "sc:painting" {
+					continue
+				}
+
+				resources := []resource2{image.Resource}
+
+				if image.Resource.Type == "oa:Choice" {
+					resources = nil
+
+					if image.Resource.Default != nil {
+						resources = append(resources, *image.Resource.Default)
+					}
+
+					resources = append(resources, image.Resource.Item...)
+				}
+
+				for _, resource := range resources {
+					refs, err := unmarshalServiceRefs(resource.Service)
+					if err != nil {
+						return nil, fmt.Errorf("canvas[%d]: %v", canvasIdx, err)
+					}
+
+					for _, ref := range refs {
+						if service, ok := ref.canvasImageService(canvas.ID, canvas.Width, canvas.Height, resource.ID, resource.Width, resource.Height); ok {
+							services = append(services, service)
+						}
+					}
+				}
+			}
+		}
+
+		// only the first sequence is required to list every canvas
+		break
+	}
+
+	return services, nil
+}
+
+// serviceRef is a service of either Presentation version, which may use JSON-LD or plain keys.
+type serviceRef struct {
+	ID      string          `json:"id"`
+	AtID    string          `json:"@id"`
+	Type    string          `json:"type"`
+	AtType  string          `json:"@type"`
+	Context json.RawMessage `json:"@context"`
+	Profile json.RawMessage `json:"profile"`
+	Width   uint32          `json:"width"`
+	Height  uint32          `json:"height"`
+}
+
+func unmarshalServiceRefs(data json.RawMessage) ([]serviceRef, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var refs []serviceRef
+
+	if data[0] == '[' {
+		err := json.Unmarshal(data, &refs)
+		if err != nil {
+			return nil, fmt.Errorf("decoding service: %v", err)
+		}
+
+		return refs, nil
+	}
+
+	var ref serviceRef
+
+	err := json.Unmarshal(data, &ref)
+	if err != nil {
+		return nil, fmt.Errorf("decoding service: %v", err)
+	}
+
+	return []serviceRef{ref}, nil
+}
+
+func (r serviceRef) serviceType() string {
+	for _, t := range []string{r.Type, r.AtType} {
+		switch t {
+		case ServiceTypeImageService3, ServiceTypeImageService2:
+			return t
+		}
+	}
+
+	contexts := unmarshalStrings(r.Context)
+
+	if containsString(contexts, iiifimageapi.Context) {
+		return ServiceTypeImageService3
+	} else if containsString(contexts, static.ImageInformationV2Context) {
+		return ServiceTypeImageService2
+	}
+
+	return ""
+}
+
+// canvasImageService returns false if the service is not a supported image service.
+func (r serviceRef) canvasImageService(canvasID string, canvasWidth, canvasHeight uint32, bodyID string, bodyWidth, bodyHeight uint32) (CanvasImageService, bool) {
+	serviceType := r.serviceType()
+	if serviceType == "" {
+		return CanvasImageService{}, false
+	}
+
+	id := r.ID
+	if id == "" {
+		id = r.AtID
+	}
+
+	width, height := r.Width, r.Height
+
+	if width == 0 || height == 0 {
+		width, height = bodyWidth, bodyHeight
+	}
+
+	if width == 0 || height == 0 {
+		width, height = canvasWidth, canvasHeight
+	}
+
+	service := CanvasImageService{
+		CanvasID:     canvasID,
+		CanvasWidth:  canvasWidth,
+		CanvasHeight: canvasHeight,
+		BodyID:       bodyID,
+		ServiceType:  serviceType,
+	}
+
+	if serviceType == ServiceTypeImageService2 {
+		var profile static.ImageInformationV2Profile
+
+		if len(r.Profile) > 0 && json.Unmarshal(r.Profile, &profile) != nil {
+			return CanvasImageService{}, false
+		}
+
+		// Presentation 3 manifests commonly use the short names of the levels
+		if level, ok := shortV2ComplianceLevels[profile.ComplianceLevel]; ok {
+			profile.ComplianceLevel = level
+		}
+
+		info, err := static.ConvertImageInformationV2(static.ImageInformationV2{
+			ID:      id,
+			Width:   width,
+			Height:  height,
+			Profile: profile,
+		}, id)
+		if err != nil {
+			// unsupported (e.g. an unknown compliance level) or unknown dimensions
+			return CanvasImageService{}, false
+		}
+
+		service.ImageInformation = info
+
+		return service, true
+	}
+
+	var profile string
+
+	if len(r.Profile) > 0 && json.Unmarshal(r.Profile, &profile) != nil {
+		return CanvasImageService{}, false
+	} else if _, ok := iiifimageapi.DefaultComplianceLevels.GetByName(iiifimageapi.ComplianceLevelName(profile)); !ok {
+		return CanvasImageService{}, false
+	} else if width == 0 || height == 0 {
+		return CanvasImageService{}, false
+	}
+
+	service.ImageInformation = iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      id,
+		Profile: iiifimageapi.ComplianceLevelName(profile),
+		Width:   width,
+		Height:  height,
+	})
+
+	return service, true
+}
+
+var shortV2ComplianceLevels = map[string]string{
+	"level0": "http://iiif.io/api/image/2/level0.json",
+	"level1": "http://iiif.io/api/image/2/level1.json",
+	"level2": "http://iiif.io/api/image/2/level2.json",
+}
+
+// unmarshalStrings decodes a string or list of strings, ignoring any other values (e.g. embedded contexts).
+func unmarshalStrings(data json.RawMessage) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var single string
+
+	if json.Unmarshal(data, &single) == nil {
+		return []string{single}
+	}
+
+	var list []interface{}
+
+	if json.Unmarshal(data, &list) != nil {
+		return nil
+	}
+
+	var strs []string
+
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+
+	return strs
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
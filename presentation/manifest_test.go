@@ -0,0 +1,230 @@
+package presentation
+
+import (
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+func TestExtractImageServices_Presentation3(t *testing.T) {
+	services, err := ExtractImageServices([]byte(`{
+		"@context": "http://iiif.io/api/presentation/3/context.json",
+		"id": "https://example.com/manifest",
+		"type": "Manifest",
+		"items": [
+			{
+				"id": "https://example.com/canvas/1",
+				"type": "Canvas",
+				"width": 1500,
+				"height": 1000,
+				"items": [{
+					"type": "AnnotationPage",
+					"items": [{
+						"type": "Annotation",
+						"motivation": "painting",
+						"body": {
+							"id": "https://example.com/iiif/a/full/max/0/default.jpg",
+							"type": "Image",
+							"width": 3000,
+							"height": 2000,
+							"service": [
+								{"id": "https://example.com/auth", "type": "AuthProbeService2"},
+								{"id": "https://example.com/iiif/a", "type": "ImageService3", "profile": "level1"}
+							]
+						}
+					}]
+				}]
+			},
+			{
+				"id": "https://example.com/canvas/2",
+				"type": "Canvas",
+				"width": 800,
+				"height": 600,
+				"items": [{
+					"type": "AnnotationPage",
+					"items": [
+						{
+							"type": "Annotation",
+							"motivation": "commenting",
+							"body": {"type": "TextualBody", "value": "ignored"}
+						},
+						{
+							"type": "Annotation",
+							"motivation": "painting",
+							"body": {
+								"type": "Choice",
+								"items": [
+									{
+										"id": "https://example.com/iiif/b/full/max/0/default.jpg",
+										"type": "Image",
+										"service": {"@id": "https://example.com/iiif/b", "@type": "ImageService2", "profile": "http://iiif.io/api/image/2/level2.json"}
+									},
+									{
+										"id": "https://example.com/c.jpg",
+										"type": "Image"
+									}
+								]
+							}
+						}
+					]
+				}]
+			}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 2, len(services); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	service := services[0]
+
+	if _e, _a := "https://example.com/canvas/1", service.CanvasID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(1500), service.CanvasWidth; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := ServiceTypeImageService3, service.ServiceType; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/a", service.ImageInformation.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.ComplianceLevel1Name, service.ImageInformation.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(3000), service.ImageInformation.Width; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.Context, service.ImageInformation.Context; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	service = services[1]
+
+	if _e, _a := "https://example.com/canvas/2", service.CanvasID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := ServiceTypeImageService2, service.ServiceType; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/b", service.ImageInformation.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.ComplianceLevel2Name, service.ImageInformation.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(800), service.ImageInformation.Width; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(600), service.ImageInformation.Height; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestExtractImageServices_Presentation2(t *testing.T) {
+	services, err := ExtractImageServices([]byte(`{
+		"@context": "http://iiif.io/api/presentation/2/context.json",
+		"@id": "https://example.com/manifest",
+		"@type": "sc:Manifest",
+		"sequences": [{
+			"@type": "sc:Sequence",
+			"canvases": [{
+				"@id": "https://example.com/canvas/1",
+				"@type": "sc:Canvas",
+				"width": 1500,
+				"height": 1000,
+				"images": [{
+					"@type": "oa:Annotation",
+					"motivation": "sc:painting",
+					"on": "https://example.com/canvas/1",
+					"resource": {
+						"@id": "https://example.com/iiif/a/full/full/0/default.jpg",
+						"@type": "dctypes:Image",
+						"service": {
+							"@context": "http://iiif.io/api/image/2/context.json",
+							"@id": "https://example.com/iiif/a",
+							"profile": ["http://iiif.io/api/image/2/level1.json", {"formats": ["png"]}]
+						}
+					}
+				}]
+			}]
+		}]
+	}`))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 1, len(services); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	service := services[0]
+
+	if _e, _a := "https://example.com/canvas/1", service.CanvasID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/a/full/full/0/default.jpg", service.BodyID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := ServiceTypeImageService2, service.ServiceType; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/a", service.ImageInformation.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.ComplianceLevel1Name, service.ImageInformation.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(1500), service.ImageInformation.Width; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestExtractImageServices_Unsupported(t *testing.T) {
+	_, err := ExtractImageServices([]byte(`{"type": "Collection"}`))
+	if err == nil {
+		t.Fatal("expected error for unsupported document")
+	}
+}
+
+func TestExtractImageServices_SkipsUnsupported(t *testing.T) {
+	services, err := ExtractImageServices([]byte(`{
+		"@context": "http://iiif.io/api/presentation/3/context.json",
+		"type": "Manifest",
+		"items": [
+			{
+				"id": "https://example.com/canvas/1",
+				"type": "Canvas",
+				"width": 300,
+				"height": 200,
+				"items": [{"items": [{"motivation": "painting", "body": {"id": "https://example.com/s1/full/max/0/default.jpg", "service": [{"id": "https://example.com/s1", "type": "ImageService2", "profile": "level2"}]}}]}]
+			},
+			{
+				"id": "https://example.com/canvas/2",
+				"type": "Canvas",
+				"width": 300,
+				"height": 200,
+				"items": [{"items": [{"motivation": "painting", "body": {"id": "https://example.com/s2/full/max/0/default.jpg", "service": [{"id": "https://example.com/s2", "type": "ImageService2", "profile": "level9"}]}}]}]
+			},
+			{
+				"id": "https://example.com/canvas/3",
+				"type": "Canvas",
+				"items": [{"items": [{"motivation": "painting", "body": {"id": "https://example.com/s3/full/max/0/default.jpg", "service": [{"id": "https://example.com/s3", "type": "ImageService2", "profile": "level1"}]}}]}]
+			},
+			{
+				"id": "https://example.com/canvas/4",
+				"type": "Canvas",
+				"width": 300,
+				"height": 200,
+				"items": [{"items": [{"motivation": "painting", "body": {"id": "https://example.com/s4/full/max/0/default.jpg", "service": [{"id": "https://example.com/s4", "type": "ImageService3", "profile": "level1"}]}}]}]
+			},
+			{
+				"id": "https://example.com/canvas/5",
+				"type": "Canvas",
+				"width": 300,
+				"height": 200,
+				"items": [{"items": [{"motivation": "painting", "body": {"id": "https://example.com/s5/full/max/0/default.jpg", "service": [{"id": "https://example.com/s5", "type": "ImageService3", "profile": "level9"}]}}]}]
+			},
+			{
+				"id": "https://example.com/canvas/6",
+				"type": "Canvas",
+				"items": [{"items": [{"motivation": "painting", "body": {"id": "https://example.com/s6/full/max/0/default.jpg", "service": [{"id": "https://example.com/s6", "type": "ImageService3", "profile": "level1"}]}}]}]
+			}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 2, len(services); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/s1", services[0].ImageInformation.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.ComplianceLevel2Name, services[0].ImageInformation.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/s4", services[1].ImageInformation.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,3 @@
+// presentation offers functions to connect image services with IIIF Presentation API documents, such as finding the
+// image services painted on the canvases of a manifest or generating a canvas painted with an image.
+package presentation
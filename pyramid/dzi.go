@@ -0,0 +1,323 @@
+package pyramid
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// DZINamespace is the XML namespace of Deep Zoom descriptors.
+const DZINamespace = "http://schemas.microsoft.com/deepzoom/2008"
+
+// DZIDescriptor is the `.dzi` XML document of a Deep Zoom image.
+type DZIDescriptor struct {
+	XMLName  xml.Name `xml:"http://schemas.microsoft.com/deepzoom/2008 Image"`
+	Format   string   `xml:"Format,attr"`
+	Overlap  uint32   `xml:"Overlap,attr"`
+	TileSize uint32   `xml:"TileSize,attr"`
+	Size     DZISize  `xml:"Size"`
+}
+
+type DZISize struct {
+	Width  uint32 `xml:"Width,attr"`
+	Height uint32 `xml:"Height,attr"`
+}
+
+// NewDZIDescriptor creates a descriptor for the tiles of info (see [NewDZIGrid]) in format.
+func NewDZIDescriptor(info iiifimageapi.ImageInformation, format string) (DZIDescriptor, error) {
+	grid, err := NewDZIGrid(info)
+	if err != nil {
+		return DZIDescriptor{}, err
+	}
+
+	return DZIDescriptor{
+		Format:   format,
+		TileSize: grid.TileSize,
+		Size: DZISize{
+			Width:  grid.ImageSize[0],
+			Height: grid.ImageSize[1],
+		},
+	}, nil
+}
+
+// ParseDZIDescriptor decodes a `.dzi` XML document.
+func ParseDZIDescriptor(data []byte) (DZIDescriptor, error) {
+	var d DZIDescriptor
+
+	err := xml.Unmarshal(data, &d)
+	if err != nil {
+		return DZIDescriptor{}, err
+	} else if d.TileSize == 0 || d.Size.Width == 0 || d.Size.Height == 0 {
+		return DZIDescriptor{}, errors.New("tile size, width, and height must not be 0")
+	}
+
+	return d, nil
+}
+
+// MarshalDocument encodes the descriptor as an XML document, including the XML declaration.
+func (d DZIDescriptor) MarshalDocument() ([]byte, error) {
+	data, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// Grid returns the tile grid described by the descriptor.
+func (d DZIDescriptor) Grid() DZIGrid {
+	return DZIGrid{
+		ImageSize: [2]uint32{d.Size.Width, d.Size.Height},
+		TileSize:  d.TileSize,
+		Overlap:   d.Overlap,
+	}
+}
+
+// DZIGrid is the pyramid of a Deep Zoom image. Level 0 is a single pixel and the highest level is the full image, so
+// each level is described by the power-of-two scale factor of its distance from the highest level.
+type DZIGrid struct {
+	ImageSize [2]uint32
+	TileSize  uint32
+	Overlap   uint32
+}
+
+// NewDZIGrid uses the first square tile configuration of info. Deep Zoom does not support rectangular tiles, and every
+// level down to a single pixel must be available, so the configuration must include the scale factor of every level.
+func NewDZIGrid(info iiifimageapi.ImageInformation) (DZIGrid, error) {
+	var missingErr error
+
+	for _, tile := range info.Tiles {
+		if tile.Width == 0 || (tile.Height != 0 && tile.Height != tile.Width) {
+			continue
+		}
+
+		grid := DZIGrid{
+			ImageSize: [2]uint32{info.Width, info.Height},
+			TileSize:  tile.Width,
+		}
+
+		if level, ok := grid.missingLevel(tile.ScaleFactors); ok {
+			if missingErr == nil {
+				missingErr = fmt.Errorf("tile scale factors do not include %d (required by level %d)", grid.LevelScaleFactor(level), level)
+			}
+
+			continue
+		}
+
+		return grid, nil
+	}
+
+	if missingErr != nil {
+		return DZIGrid{}, missingErr
+	}
+
+	return DZIGrid{}, errors.New("image information does not describe square tiles")
+}
+
+// missingLevel returns the first level whose scale factor is not in scaleFactors.
+func (g DZIGrid) missingLevel(scaleFactors []uint32) (int, bool) {
+	for level := g.MaxLevel(); level >= 0; level-- {
+		scaleFactor := g.LevelScaleFactor(level)
+
+		var found bool
+
+		for _, v := range scaleFactors {
+			if v == scaleFactor {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return level, true
+		}
+	}
+
+	return 0, false
+}
+
+// ImageInformationTile returns the equivalent tile configuration, with a scale factor for every level. Grids with
+// overlap have no equivalent.
+func (g DZIGrid) ImageInformationTile() (iiifimageapi.ImageInformationTile, error) {
+	if g.Overlap != 0 {
+		return iiifimageapi.ImageInformationTile{}, errors.New("tiles with overlap are not supported")
+	}
+
+	tile := iiifimageapi.ImageInformationTile{
+		Width: g.TileSize,
+	}
+
+	for level := g.MaxLevel(); level >= 0; level-- {
+		tile.ScaleFactors = append(tile.ScaleFactors, g.LevelScaleFactor(level))
+	}
+
+	return tile, nil
+}
+
+// MaxLevel returns the level of the full image.
+func (g DZIGrid) MaxLevel() int {
+	return maxLevel(g.ImageSize)
+}
+
+// LevelScaleFactor returns the reduction of level compared to the full image.
+func (g DZIGrid) LevelScaleFactor(level int) uint32 {
+	return 1 << uint(g.MaxLevel()-level)
+}
+
+// LevelSize returns the dimensions of the image at level.
+func (g DZIGrid) LevelSize(level int) [2]uint32 {
+	return levelSize(g.ImageSize, g.LevelScaleFactor(level))
+}
+
+// LevelTiles returns the number of columns and rows of level.
+func (g DZIGrid) LevelTiles(level int) [2]uint32 {
+	size := g.LevelSize(level)
+
+	return [2]uint32{
+		(size[0] + g.TileSize - 1) / g.TileSize,
+		(size[1] + g.TileSize - 1) / g.TileSize,
+	}
+}
+
+// DZITile identifies a single tile of a [DZIGrid].
+type DZITile struct {
+	Level  int
+	Column uint32
+	Row    uint32
+}
+
+// Path returns the `{level}/{column}_{row}.{format}` path of the tile, relative to the `{name}_files` directory.
+func (t DZITile) Path(format string) string {
+	return fmt.Sprintf("%d/%d_%d.%s", t.Level, t.Column, t.Row, format)
+}
+
+// ParseDZITilePath parses a path of [DZITile.Path]. A leading `{name}_files/` directory is ignored.
+func ParseDZITilePath(path string) (DZITile, string, error) {
+	if idx := strings.Index(path, "_files/"); idx >= 0 {
+		path = path[idx+len("_files/"):]
+	}
+
+	pathSplit := strings.Split(path, "/")
+	if len(pathSplit) != 2 {
+		return DZITile{}, "", fmt.Errorf("invalid path format (expecting `{level}/{column}_{row}.{format}`)")
+	}
+
+	level, err := strconv.ParseUint(pathSplit[0], 10, 8)
+	if err != nil {
+		return DZITile{}, "", fmt.Errorf("parsing level: %v", err)
+	}
+
+	fileSplit := strings.SplitN(pathSplit[1], ".", 2)
+	if len(fileSplit) != 2 || fileSplit[1] == "" {
+		return DZITile{}, "", fmt.Errorf("invalid file format (expecting `{column}_{row}.{format}`)")
+	}
+
+	positionSplit := strings.Split(fileSplit[0], "_")
+	if len(positionSplit) != 2 {
+		return DZITile{}, "", fmt.Errorf("invalid file format (expecting `{column}_{row}.{format}`)")
+	}
+
+	column, err := strconv.ParseUint(positionSplit[0], 10, 32)
+	if err != nil {
+		return DZITile{}, "", fmt.Errorf("parsing column: %v", err)
+	}
+
+	row, err := strconv.ParseUint(positionSplit[1], 10, 32)
+	if err != nil {
+		return DZITile{}, "", fmt.Errorf("parsing row: %v", err)
+	}
+
+	return DZITile{
+		Level:  int(level),
+		Column: uint32(column),
+		Row:    uint32(row),
+	}, fileSplit[1], nil
+}
+
+// TileValue returns the full image region and size of tile, including any overlap.
+func (g DZIGrid) TileValue(tile DZITile) (pixelset.Value, bool) {
+	if tile.Level < 0 || tile.Level > g.MaxLevel() {
+		return pixelset.Value{}, false
+	}
+
+	rect := [4]uint32{
+		tile.Column * g.TileSize,
+		tile.Row * g.TileSize,
+		g.TileSize + g.Overlap,
+		g.TileSize + g.Overlap,
+	}
+
+	if tile.Column > 0 {
+		rect[0] -= g.Overlap
+		rect[2] += g.Overlap
+	}
+
+	if tile.Row > 0 {
+		rect[1] -= g.Overlap
+		rect[3] += g.Overlap
+	}
+
+	tiles := g.LevelTiles(tile.Level)
+	if tile.Column >= tiles[0] || tile.Row >= tiles[1] {
+		return pixelset.Value{}, false
+	}
+
+	return levelValue(g.ImageSize, g.LevelScaleFactor(tile.Level), rect)
+}
+
+// TileForValue finds the tile whose region and size is value.
+func (g DZIGrid) TileForValue(value pixelset.Value) (DZITile, bool) {
+	for level := g.MaxLevel(); level >= 0; level-- {
+		scaledTileSize := g.TileSize * g.LevelScaleFactor(level)
+
+		tile := DZITile{
+			Level:  level,
+			Column: (value.Region[0] + g.Overlap*g.LevelScaleFactor(level)) / scaledTileSize,
+			Row:    (value.Region[1] + g.Overlap*g.LevelScaleFactor(level)) / scaledTileSize,
+		}
+
+		if actual, ok := g.TileValue(tile); ok && actual == value {
+			return tile, true
+		}
+	}
+
+	return DZITile{}, false
+}
+
+// Enumerate lists every tile of every level.
+func (g DZIGrid) Enumerate() []DZITile {
+	var tiles []DZITile
+
+	for level := 0; level <= g.MaxLevel(); level++ {
+		count := g.LevelTiles(level)
+
+		for row := uint32(0); row < count[1]; row++ {
+			for column := uint32(0); column < count[0]; column++ {
+				tiles = append(tiles, DZITile{
+					Level:  level,
+					Column: column,
+					Row:    row,
+				})
+			}
+		}
+	}
+
+	return tiles
+}
+
+// Resolve translates a request for tile into the equivalent image request in format.
+func (g DZIGrid) Resolve(tile DZITile, format string, opts imagerequest.ResolveOptions) (imagerequest.ResolvedParams, error) {
+	value, ok := g.TileValue(tile)
+	if !ok {
+		return imagerequest.ResolvedParams{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("tile (%s) does not exist", tile.Path(format)))
+	}
+
+	return imagerequest.NewParsedParamsFromPixelset(value, "default", format).Resolve(opts)
+}
@@ -0,0 +1,204 @@
+package pyramid
+
+import (
+	"strings"
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+func exampleImageInformation() iiifimageapi.ImageInformation {
+	return iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   100,
+		Height:  60,
+		Tiles: []iiifimageapi.ImageInformationTile{
+			{Width: 32, ScaleFactors: []uint32{1, 2, 4}},
+		},
+	})
+}
+
+// exampleDZIImageInformation is [exampleImageInformation] with the scale factors of every Deep Zoom level.
+func exampleDZIImageInformation() iiifimageapi.ImageInformation {
+	info := exampleImageInformation()
+	info.Tiles = []iiifimageapi.ImageInformationTile{
+		{Width: 32, ScaleFactors: []uint32{1, 2, 4, 8, 16, 32, 64, 128}},
+	}
+
+	return info
+}
+
+func TestDZIGrid_Levels(t *testing.T) {
+	grid, err := NewDZIGrid(exampleDZIImageInformation())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := 7, grid.MaxLevel(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, tc := range []struct {
+		level int
+		size  [2]uint32
+		tiles [2]uint32
+	}{
+		{level: 7, size: [2]uint32{100, 60}, tiles: [2]uint32{4, 2}},
+		{level: 6, size: [2]uint32{50, 30}, tiles: [2]uint32{2, 1}},
+		{level: 5, size: [2]uint32{25, 15}, tiles: [2]uint32{1, 1}},
+		{level: 0, size: [2]uint32{1, 1}, tiles: [2]uint32{1, 1}},
+	} {
+		if _e, _a := tc.size, grid.LevelSize(tc.level); _e != _a {
+			t.Fatalf("%d: expected `%v` but got: %v", tc.level, _e, _a)
+		} else if _e, _a := tc.tiles, grid.LevelTiles(tc.level); _e != _a {
+			t.Fatalf("%d: expected `%v` but got: %v", tc.level, _e, _a)
+		}
+	}
+}
+
+func TestDZIGrid_TileValue(t *testing.T) {
+	grid, _ := NewDZIGrid(exampleDZIImageInformation())
+
+	value, ok := grid.TileValue(DZITile{Level: 7, Column: 3, Row: 1})
+	if !ok {
+		t.Fatal("expected tile to exist")
+	} else if _e, _a := (pixelset.Value{Region: [4]uint32{96, 32, 4, 28}, Size: [2]uint32{4, 28}}), value; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _, ok := grid.TileValue(DZITile{Level: 7, Column: 4, Row: 0}); ok {
+		t.Fatal("expected tile to not exist")
+	}
+
+	grid.Overlap = 1
+
+	value, _ = grid.TileValue(DZITile{Level: 7, Column: 1, Row: 0})
+	if _e, _a := (pixelset.Value{Region: [4]uint32{31, 0, 34, 33}, Size: [2]uint32{34, 33}}), value; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestDZIGrid_RoundTrip(t *testing.T) {
+	info := exampleDZIImageInformation()
+	grid, _ := NewDZIGrid(info)
+
+	for _, tile := range grid.Enumerate() {
+		value, ok := grid.TileValue(tile)
+		if !ok {
+			t.Fatalf("%v: expected tile to exist", tile)
+		}
+
+		actual, ok := grid.TileForValue(value)
+		if !ok {
+			t.Fatalf("%v: expected tile for value %v", tile, value)
+		} else if _e, _a := tile, actual; _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+	}
+
+	for _, value := range pixelset.NewImageTileDomain([2]uint32{info.Width, info.Height}, info.Tiles[0]).Enumerate() {
+		if value.Size[0] == 0 || value.Size[1] == 0 {
+			continue
+		} else if _, ok := grid.TileForValue(value); !ok {
+			t.Fatalf("expected tile for value %v", value)
+		}
+	}
+
+	tileSpec, err := grid.ImageInformationTile()
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 8, len(tileSpec.ScaleFactors); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestNewDZIGrid_MissingScaleFactors(t *testing.T) {
+	_, err := NewDZIGrid(exampleImageInformation())
+	if err == nil {
+		t.Fatal("expected error but got none")
+	} else if _e, _a := "tile scale factors do not include 8 (required by level 4)", err.Error(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	info := exampleImageInformation()
+	info.Tiles = append(info.Tiles, exampleDZIImageInformation().Tiles...)
+
+	grid, err := NewDZIGrid(info)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := uint32(32), grid.TileSize; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, tile := range grid.Enumerate() {
+		_, err := grid.Resolve(tile, "jpg", imagerequest.ResolveOptions{
+			ImageInformation: info,
+			DefaultQuality:   "color",
+		})
+		if err != nil {
+			t.Fatalf("%v: expected `nil` but got: %v", tile, err)
+		}
+	}
+}
+
+func TestDZIDescriptor(t *testing.T) {
+	descriptor, err := NewDZIDescriptor(exampleDZIImageInformation(), "jpg")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	data, err := descriptor.MarshalDocument()
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<Image xmlns="http://schemas.microsoft.com/deepzoom/2008" Format="jpg" Overlap="0" TileSize="32">`,
+		`<Size Width="100" Height="60"></Size>`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Fatalf("expected `%s` to contain `%s`", data, expected)
+		}
+	}
+
+	parsed, err := ParseDZIDescriptor(data)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := descriptor.Grid(), parsed.Grid(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestDZIGrid_Resolve(t *testing.T) {
+	info := exampleDZIImageInformation()
+	grid, _ := NewDZIGrid(info)
+
+	tile, format, err := ParseDZITilePath("example_files/6/1_0.jpg")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := (DZITile{Level: 6, Column: 1, Row: 0}), tile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "6/1_0.jpg", tile.Path(format); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	resolved, err := grid.Resolve(tile, format, imagerequest.ResolveOptions{
+		ImageInformation:    info,
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "64,0,36,60/18,30/0/default.jpg", resolved.Canonical().String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, path := range []string{"6/1.jpg", "x/1_0.jpg", "6/1_0", "6/1_0/2.jpg"} {
+		if _, _, err := ParseDZITilePath(path); err == nil {
+			t.Fatalf("%s: expected error but got none", path)
+		}
+	}
+}
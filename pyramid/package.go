@@ -0,0 +1,4 @@
+// pyramid offers conversions between the tile grids of an image and other power-of-two tile pyramids, such as Deep
+// Zoom (DZI), Zoomify, and XYZ slippy maps, so the same tiles may be served or exported for viewers which only support
+// those formats.
+package pyramid
@@ -0,0 +1,73 @@
+package pyramid
+
+import (
+	"math"
+
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// levelSize returns the size of the image reduced by scaleFactor, rounded up.
+func levelSize(imageSize [2]uint32, scaleFactor uint32) [2]uint32 {
+	return [2]uint32{
+		(imageSize[0] + scaleFactor - 1) / scaleFactor,
+		(imageSize[1] + scaleFactor - 1) / scaleFactor,
+	}
+}
+
+// levelValue converts a rectangle in the pixels of a reduced level to its full image region and size. The rectangle
+// is clipped to the level.
+func levelValue(imageSize [2]uint32, scaleFactor uint32, rect [4]uint32) (pixelset.Value, bool) {
+	size := levelSize(imageSize, scaleFactor)
+
+	if rect[0] >= size[0] || rect[1] >= size[1] || rect[2] == 0 || rect[3] == 0 {
+		return pixelset.Value{}, false
+	}
+
+	if rect[0]+rect[2] > size[0] {
+		rect[2] = size[0] - rect[0]
+	}
+
+	if rect[1]+rect[3] > size[1] {
+		rect[3] = size[1] - rect[1]
+	}
+
+	region := [4]uint32{
+		rect[0] * scaleFactor,
+		rect[1] * scaleFactor,
+		rect[2] * scaleFactor,
+		rect[3] * scaleFactor,
+	}
+
+	if region[0]+region[2] > imageSize[0] {
+		region[2] = imageSize[0] - region[0]
+	}
+
+	if region[1]+region[3] > imageSize[1] {
+		region[3] = imageSize[1] - region[1]
+	}
+
+	return pixelset.Value{
+		Region: region,
+		Size: [2]uint32{
+			uint32(math.Ceil(float64(region[2]) / float64(scaleFactor))),
+			uint32(math.Ceil(float64(region[3]) / float64(scaleFactor))),
+		},
+	}, true
+}
+
+// maxLevel returns the number of halvings until the longest side of the image is 1 pixel.
+func maxLevel(imageSize [2]uint32) int {
+	longest := imageSize[0]
+	if imageSize[1] > longest {
+		longest = imageSize[1]
+	}
+
+	level := 0
+
+	for longest > 1 {
+		longest = (longest + 1) / 2
+		level++
+	}
+
+	return level
+}
@@ -0,0 +1,194 @@
+package pyramid
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// XYZGrid is the pyramid of an image as `/{z}/{x}/{y}` slippy-map tiles. Zoom 0 is the smallest reduction which fits
+// in a single tile and the highest zoom is the full image, so the zoom is the inverse of the scale factor.
+//
+// Slippy maps expect every zoom to be a 2^z by 2^z grid of full tiles, so the image is anchored at the top-left of
+// the grid and padded on the right and bottom. Tiles which contain no part of the image are entirely padding.
+type XYZGrid struct {
+	ImageSize [2]uint32
+	TileSize  uint32
+}
+
+// NewXYZGrid uses the first square tile configuration of info.
+func NewXYZGrid(info iiifimageapi.ImageInformation) (XYZGrid, error) {
+	for _, tile := range info.Tiles {
+		if tile.Width == 0 || (tile.Height != 0 && tile.Height != tile.Width) {
+			continue
+		}
+
+		return XYZGrid{
+			ImageSize: [2]uint32{info.Width, info.Height},
+			TileSize:  tile.Width,
+		}, nil
+	}
+
+	return XYZGrid{}, errors.New("image information does not describe square tiles")
+}
+
+// MaxZoom returns the zoom of the full image.
+func (g XYZGrid) MaxZoom() int {
+	return ZoomifyGrid(g).Tiers() - 1
+}
+
+// ZoomScaleFactor returns the reduction of zoom compared to the full image.
+func (g XYZGrid) ZoomScaleFactor(zoom int) uint32 {
+	return 1 << uint(g.MaxZoom()-zoom)
+}
+
+// ScaleFactorZoom returns the zoom of a scale factor, if it is a power of two within the grid.
+func (g XYZGrid) ScaleFactorZoom(scaleFactor uint32) (int, bool) {
+	for zoom := g.MaxZoom(); zoom >= 0; zoom-- {
+		if g.ZoomScaleFactor(zoom) == scaleFactor {
+			return zoom, true
+		}
+	}
+
+	return 0, false
+}
+
+// ZoomTiles returns the number of columns and rows of zoom which contain part of the image.
+func (g XYZGrid) ZoomTiles(zoom int) [2]uint32 {
+	return ZoomifyGrid(g).TierTiles(zoom)
+}
+
+// XYZTile identifies a single tile of an [XYZGrid].
+type XYZTile struct {
+	Z int
+	X uint32
+	Y uint32
+}
+
+// Path returns the `{z}/{x}/{y}.{format}` path of the tile.
+func (t XYZTile) Path(format string) string {
+	return fmt.Sprintf("%d/%d/%d.%s", t.Z, t.X, t.Y, format)
+}
+
+// ParseXYZTilePath parses a path of [XYZTile.Path]. A leading slash is ignored.
+func ParseXYZTilePath(path string) (XYZTile, string, error) {
+	pathSplit := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(pathSplit) != 3 {
+		return XYZTile{}, "", errors.New("invalid path format (expecting `{z}/{x}/{y}.{format}`)")
+	}
+
+	fileSplit := strings.SplitN(pathSplit[2], ".", 2)
+	if len(fileSplit) != 2 || fileSplit[1] == "" {
+		return XYZTile{}, "", errors.New("invalid file format (expecting `{y}.{format}`)")
+	}
+
+	z, err := strconv.ParseUint(pathSplit[0], 10, 5)
+	if err != nil {
+		return XYZTile{}, "", fmt.Errorf("parsing z: %v", err)
+	}
+
+	x, err := strconv.ParseUint(pathSplit[1], 10, 32)
+	if err != nil {
+		return XYZTile{}, "", fmt.Errorf("parsing x: %v", err)
+	}
+
+	y, err := strconv.ParseUint(fileSplit[0], 10, 32)
+	if err != nil {
+		return XYZTile{}, "", fmt.Errorf("parsing y: %v", err)
+	}
+
+	return XYZTile{
+		Z: int(z),
+		X: uint32(x),
+		Y: uint32(y),
+	}, fileSplit[1], nil
+}
+
+// Contains returns true if tile is within the 2^z by 2^z grid of its zoom, even if it is entirely padding.
+func (g XYZGrid) Contains(tile XYZTile) bool {
+	if tile.Z < 0 || tile.Z > g.MaxZoom() {
+		return false
+	}
+
+	return uint64(tile.X) < 1<<uint(tile.Z) && uint64(tile.Y) < 1<<uint(tile.Z)
+}
+
+// TileValue returns the full image region and size of the part of the image within tile. It returns false if tile is
+// entirely padding or outside of the grid.
+func (g XYZGrid) TileValue(tile XYZTile) (pixelset.Value, bool) {
+	if !g.Contains(tile) {
+		return pixelset.Value{}, false
+	}
+
+	return ZoomifyGrid(g).TileValue(ZoomifyTile{
+		Tier:   tile.Z,
+		Column: tile.X,
+		Row:    tile.Y,
+	})
+}
+
+// TileContent returns the area of the output tile which is covered by the image. The rest of the tile is padding.
+func (g XYZGrid) TileContent(tile XYZTile) image.Rectangle {
+	value, ok := g.TileValue(tile)
+	if !ok {
+		return image.Rectangle{}
+	}
+
+	return image.Rect(0, 0, int(value.Size[0]), int(value.Size[1]))
+}
+
+// TileForValue finds the tile whose image content is value.
+func (g XYZGrid) TileForValue(value pixelset.Value) (XYZTile, bool) {
+	tile, ok := ZoomifyGrid(g).TileForValue(value)
+	if !ok {
+		return XYZTile{}, false
+	}
+
+	return XYZTile{
+		Z: tile.Tier,
+		X: tile.Column,
+		Y: tile.Row,
+	}, true
+}
+
+// Enumerate lists every tile which contains part of the image, for zooms whose scale factor is in scaleFactors. If
+// scaleFactors is empty, every zoom is included.
+func (g XYZGrid) Enumerate(scaleFactors ...uint32) []XYZTile {
+	included := map[uint32]struct{}{}
+
+	for _, scaleFactor := range scaleFactors {
+		included[scaleFactor] = struct{}{}
+	}
+
+	var tiles []XYZTile
+
+	for _, tile := range ZoomifyGrid(g).Enumerate() {
+		if _, ok := included[g.ZoomScaleFactor(tile.Tier)]; !ok && len(included) > 0 {
+			continue
+		}
+
+		tiles = append(tiles, XYZTile{
+			Z: tile.Tier,
+			X: tile.Column,
+			Y: tile.Row,
+		})
+	}
+
+	return tiles
+}
+
+// Resolve translates tile into the equivalent image request in format.
+func (g XYZGrid) Resolve(tile XYZTile, format string, opts imagerequest.ResolveOptions) (imagerequest.ResolvedParams, error) {
+	value, ok := g.TileValue(tile)
+	if !ok {
+		return imagerequest.ResolvedParams{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("tile (%s) does not contain the image", tile.Path(format)))
+	}
+
+	return imagerequest.NewParsedParamsFromPixelset(value, "default", format).Resolve(opts)
+}
@@ -0,0 +1,238 @@
+package pyramid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/client"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+	"github.com/dpb587/go-iiif-image-api-v3/stitch"
+)
+
+// XYZOptions configures how [XYZGrid] tiles are rendered.
+type XYZOptions struct {
+	// Source provides the image content of each tile. It receives the same parameters as [XYZGrid.Resolve].
+	Source stitch.TileSource
+
+	// Format is the format requested from Source. If empty, the first preferred format of the image is used,
+	// otherwise "jpg".
+	Format string
+
+	// Formats is used to find and encode the format of the served tile. If nil, the default formats are used.
+	Formats *iiifimageapi.FormatRegistry
+}
+
+func (o XYZOptions) getFormat(info iiifimageapi.ImageInformation) string {
+	if o.Format != "" {
+		return o.Format
+	} else if len(info.PreferredFormats) > 0 {
+		return info.PreferredFormats[0]
+	}
+
+	return "jpg"
+}
+
+func (o XYZOptions) getFormats() *iiifimageapi.FormatRegistry {
+	if o.Formats != nil {
+		return o.Formats
+	}
+
+	return iiifimageapi.DefaultFormats
+}
+
+// XYZRenderer renders the full, padded tiles of an image.
+type XYZRenderer struct {
+	info         iiifimageapi.ImageInformation
+	grid         XYZGrid
+	scaleFactors []uint32
+	options      XYZOptions
+}
+
+// NewXYZRenderer creates a renderer for the tiles of info (see [NewXYZGrid]).
+func NewXYZRenderer(info iiifimageapi.ImageInformation, opts XYZOptions) (*XYZRenderer, error) {
+	if opts.Source == nil {
+		return nil, errors.New("source must not be nil")
+	}
+
+	grid, err := NewXYZGrid(info)
+	if err != nil {
+		return nil, err
+	}
+
+	var scaleFactors []uint32
+
+	for _, tile := range info.Tiles {
+		if tile.Width == grid.TileSize && (tile.Height == 0 || tile.Height == tile.Width) {
+			scaleFactors = tile.ScaleFactors
+
+			break
+		}
+	}
+
+	return &XYZRenderer{
+		info:         info,
+		grid:         grid,
+		scaleFactors: scaleFactors,
+		options:      opts,
+	}, nil
+}
+
+// Grid returns the tile grid of the image.
+func (r *XYZRenderer) Grid() XYZGrid {
+	return r.grid
+}
+
+// Tile returns tile as a square image of the tile size. The image content is drawn at the top-left and the rest is
+// transparent (or black in formats without an alpha channel). Tiles which are entirely padding are fully transparent.
+// Errors of the source are wrapped.
+func (r *XYZRenderer) Tile(ctx context.Context, tile XYZTile) (image.Image, error) {
+	if !r.grid.Contains(tile) {
+		return nil, fmt.Errorf("tile (%s) is outside of the grid", tile.Path("*"))
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, int(r.grid.TileSize), int(r.grid.TileSize)))
+
+	if _, ok := r.grid.TileValue(tile); !ok {
+		return out, nil
+	}
+
+	params, err := r.grid.Resolve(tile, r.options.getFormat(r.info), imagerequest.ResolveOptions{
+		ImageInformation:    r.info,
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolving tile (%s): %v", tile.Path("*"), err)
+	}
+
+	img, err := r.options.Source.Tile(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("tile %s: %w", params.Canonical(), err)
+	}
+
+	draw.Draw(out, r.grid.TileContent(tile), img, img.Bounds().Min, draw.Src)
+
+	return out, nil
+}
+
+// ServeHTTP serves `/{z}/{x}/{y}.{ext}` requests, typically behind [http.StripPrefix]. The extension must be a known
+// format, and coordinates outside of the grid or of zooms which are not exported (see [XYZRenderer.Export]) are not
+// found. Tiles missing from the source are not found, and other errors of the source are a bad gateway.
+func (r *XYZRenderer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	tile, ext, err := ParseXYZTilePath(req.URL.Path)
+	if err != nil || !r.grid.Contains(tile) || !r.exported(tile.Z) {
+		http.NotFound(w, req)
+
+		return
+	}
+
+	format, ok := r.options.getFormats().GetByExtension(ext)
+	if !ok || format.Encoder == nil {
+		http.NotFound(w, req)
+
+		return
+	}
+
+	img, err := r.Tile(req.Context(), tile)
+	if err != nil {
+		http.Error(w, err.Error(), tileErrorStatusCode(err))
+
+		return
+	}
+
+	buf := &bytes.Buffer{}
+
+	err = r.options.getFormats().Encode(buf, format.Name, img)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", format.MediaType)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", buf.Len()))
+	w.WriteHeader(http.StatusOK)
+
+	if req.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+// exported returns true if the scale factor of zoom is advertised, or no scale factors are advertised.
+func (r *XYZRenderer) exported(zoom int) bool {
+	if len(r.scaleFactors) == 0 {
+		return true
+	}
+
+	scaleFactor := r.grid.ZoomScaleFactor(zoom)
+
+	for _, v := range r.scaleFactors {
+		if v == scaleFactor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tileErrorStatusCode returns the status of a failure to render a tile, distinguishing tiles which are missing from
+// the source (e.g. a local file system or a remote service which responded 404 Not Found) from other failures.
+func tileErrorStatusCode(err error) int {
+	var statusErr client.StatusError
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return http.StatusNotFound
+	} else if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadGateway
+}
+
+// Export writes every tile which contains part of the image to out as `{z}/{x}/{y}.{ext}`. Tiles which are entirely
+// padding are skipped since slippy-map viewers tolerate missing tiles outside of the image. Only zooms whose scale
+// factor is advertised by the tile configuration of the grid are written, since a source (e.g. of a level0 image) may
+// not be able to provide the others; a configuration without scale factors writes every zoom.
+func (r *XYZRenderer) Export(ctx context.Context, out static.Output, ext string) error {
+	format, ok := r.options.getFormats().GetByExtension(strings.TrimPrefix(ext, "."))
+	if !ok {
+		return fmt.Errorf("unsupported extension: %s", ext)
+	}
+
+	for _, tile := range r.grid.Enumerate(r.scaleFactors...) {
+		img, err := r.Tile(ctx, tile)
+		if err != nil {
+			return err
+		}
+
+		path := tile.Path(strings.TrimPrefix(format.Extension(), "."))
+
+		err = out.WriteFile(path, func(w io.Writer) error {
+			return r.options.getFormats().Encode(w, format.Name, img)
+		})
+		if err != nil {
+			return fmt.Errorf("writing %s: %v", path, err)
+		}
+	}
+
+	return nil
+}
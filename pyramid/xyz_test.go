@@ -0,0 +1,318 @@
+package pyramid
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dpb587/go-iiif-image-api-v3/client"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+	"github.com/dpb587/go-iiif-image-api-v3/render"
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+	"github.com/dpb587/go-iiif-image-api-v3/stitch"
+)
+
+func exampleXYZRenderer(t *testing.T) *XYZRenderer {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 60))
+
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 100; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x * 2), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+
+	r, err := NewXYZRenderer(exampleImageInformation(), XYZOptions{
+		Source: stitch.TileSourceFunc(func(_ context.Context, params imagerequest.ResolvedParams) (image.Image, error) {
+			return render.Renderer{}.Render(src, params)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	return r
+}
+
+func TestXYZGrid_Zooms(t *testing.T) {
+	grid, err := NewXYZGrid(exampleImageInformation())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := 2, grid.MaxZoom(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, tc := range []struct {
+		zoom        int
+		scaleFactor uint32
+		tiles       [2]uint32
+	}{
+		{zoom: 2, scaleFactor: 1, tiles: [2]uint32{4, 2}},
+		{zoom: 1, scaleFactor: 2, tiles: [2]uint32{2, 1}},
+		{zoom: 0, scaleFactor: 4, tiles: [2]uint32{1, 1}},
+	} {
+		if _e, _a := tc.scaleFactor, grid.ZoomScaleFactor(tc.zoom); _e != _a {
+			t.Fatalf("%d: expected `%v` but got: %v", tc.zoom, _e, _a)
+		} else if _e, _a := tc.tiles, grid.ZoomTiles(tc.zoom); _e != _a {
+			t.Fatalf("%d: expected `%v` but got: %v", tc.zoom, _e, _a)
+		} else if zoom, ok := grid.ScaleFactorZoom(tc.scaleFactor); !ok || zoom != tc.zoom {
+			t.Fatalf("%d: expected `%v` but got: %v", tc.zoom, tc.zoom, zoom)
+		}
+	}
+
+	if _, ok := grid.ScaleFactorZoom(3); ok {
+		t.Fatal("expected scale factor to have no zoom")
+	}
+}
+
+func TestXYZGrid_TileValue(t *testing.T) {
+	grid, _ := NewXYZGrid(exampleImageInformation())
+
+	value, ok := grid.TileValue(XYZTile{Z: 2, X: 3, Y: 1})
+	if !ok {
+		t.Fatal("expected tile to contain the image")
+	} else if _e, _a := (pixelset.Value{Region: [4]uint32{96, 32, 4, 28}, Size: [2]uint32{4, 28}}), value; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := image.Rect(0, 0, 4, 28), grid.TileContent(XYZTile{Z: 2, X: 3, Y: 1}); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// padding within the grid
+	if !grid.Contains(XYZTile{Z: 2, X: 0, Y: 3}) {
+		t.Fatal("expected tile to be within the grid")
+	} else if _, ok := grid.TileValue(XYZTile{Z: 2, X: 0, Y: 3}); ok {
+		t.Fatal("expected tile to be padding")
+	}
+
+	// outside of the grid
+	if grid.Contains(XYZTile{Z: 0, X: 1, Y: 0}) {
+		t.Fatal("expected tile to be outside of the grid")
+	} else if grid.Contains(XYZTile{Z: 3}) {
+		t.Fatal("expected zoom to be outside of the grid")
+	}
+}
+
+func TestXYZGrid_RoundTrip(t *testing.T) {
+	grid, _ := NewXYZGrid(exampleImageInformation())
+
+	tiles := grid.Enumerate()
+	if _e, _a := 11, len(tiles); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	domain := pixelset.NewImageDomain(exampleImageInformation())
+
+	for _, tile := range tiles {
+		value, ok := grid.TileValue(tile)
+		if !ok {
+			t.Fatalf("%s: expected tile to contain the image", tile.Path("png"))
+		} else if !domain.Contains(value) {
+			t.Fatalf("%s: expected value (%v) to be advertised", tile.Path("png"), value)
+		}
+
+		actual, ok := grid.TileForValue(value)
+		if !ok || actual != tile {
+			t.Fatalf("%s: expected `%v` but got: %v", tile.Path("png"), tile, actual)
+		}
+
+		parsed, format, err := ParseXYZTilePath("/" + tile.Path("png"))
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		} else if parsed != tile || format != "png" {
+			t.Fatalf("expected `%v` but got: %v (%s)", tile, parsed, format)
+		}
+	}
+
+	if _e, _a := []XYZTile{{Z: 0}}, grid.Enumerate(4); !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestXYZGrid_Resolve(t *testing.T) {
+	grid, _ := NewXYZGrid(exampleImageInformation())
+
+	params, err := grid.Resolve(XYZTile{Z: 1, X: 1, Y: 0}, "jpg", imagerequest.ResolveOptions{
+		ImageInformation: exampleImageInformation(),
+		DefaultQuality:   "color",
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "64,0,36,60/18,30/0/default.jpg", params.Canonical().String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	_, err = grid.Resolve(XYZTile{Z: 1, X: 0, Y: 1}, "jpg", imagerequest.ResolveOptions{
+		ImageInformation: exampleImageInformation(),
+		DefaultQuality:   "color",
+	})
+	if err == nil {
+		t.Fatal("expected error for padding tile")
+	}
+}
+
+func TestXYZRenderer_Tile(t *testing.T) {
+	r := exampleXYZRenderer(t)
+
+	img, err := r.Tile(context.Background(), XYZTile{Z: 2, X: 3, Y: 1})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := image.Rect(0, 0, 32, 32), img.Bounds(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _e, _a := (color.RGBA{R: 192, G: 128, B: 128, A: 255}), color.RGBAModel.Convert(img.At(0, 0)); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.RGBA{}), color.RGBAModel.Convert(img.At(4, 0)); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.RGBA{}), color.RGBAModel.Convert(img.At(0, 28)); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestXYZRenderer_ServeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.StripPrefix("/tiles", exampleXYZRenderer(t)))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{path: "/tiles/0/0/0.png", status: http.StatusOK},
+		{path: "/tiles/2/3/1.png", status: http.StatusOK},
+		{path: "/tiles/2/0/3.png", status: http.StatusOK},
+		{path: "/tiles/0/1/0.png", status: http.StatusNotFound},
+		{path: "/tiles/3/0/0.png", status: http.StatusNotFound},
+		{path: "/tiles/0/0/0.unknown", status: http.StatusNotFound},
+		{path: "/tiles/0/0.png", status: http.StatusNotFound},
+	} {
+		res, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.path, err)
+		}
+
+		if _e, _a := tc.status, res.StatusCode; _e != _a {
+			res.Body.Close()
+			t.Fatalf("%s: expected `%v` but got: %v", tc.path, _e, _a)
+		} else if tc.status != http.StatusOK {
+			res.Body.Close()
+
+			continue
+		}
+
+		if _e, _a := "image/png", res.Header.Get("Content-Type"); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.path, _e, _a)
+		}
+
+		img, err := png.Decode(res.Body)
+		res.Body.Close()
+
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.path, err)
+		} else if _e, _a := image.Rect(0, 0, 32, 32), img.Bounds(); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.path, _e, _a)
+		}
+	}
+}
+
+func TestXYZRenderer_Export(t *testing.T) {
+	out := static.NewMemoryOutput()
+
+	err := exampleXYZRenderer(t).Export(context.Background(), out, "png")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	paths := out.Paths()
+	if _e, _a := 11, len(paths); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "0/0/0.png", paths[0]; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestXYZRenderer_Export_ScaleFactors(t *testing.T) {
+	info := exampleImageInformation()
+	info.Tiles[0].ScaleFactors = []uint32{1, 2}
+
+	r, err := NewXYZRenderer(info, XYZOptions{
+		Source: stitch.TileSourceFunc(func(_ context.Context, params imagerequest.ResolvedParams) (image.Image, error) {
+			if _, err := params.Canonical().Resolve(imagerequest.ResolveOptions{ImageInformation: info, DefaultQuality: "color"}); err != nil {
+				return nil, err
+			}
+
+			return image.NewRGBA(image.Rect(0, 0, int(params.SizePixels()[0]), int(params.SizePixels()[1]))), nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	out := static.NewMemoryOutput()
+
+	err = r.Export(context.Background(), out, "png")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	paths := out.Paths()
+	if _e, _a := 10, len(paths); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, path := range paths {
+		if path == "0/0/0.png" {
+			t.Fatalf("expected zoom 0 to not be exported but got: %v", path)
+		}
+	}
+}
+
+func TestXYZRenderer_ServeHTTP_Errors(t *testing.T) {
+	info := exampleImageInformation()
+	info.Tiles[0].ScaleFactors = []uint32{1, 2}
+
+	for _, tc := range []struct {
+		name   string
+		source stitch.TileSource
+		path   string
+		status int
+	}{
+		{name: "not exported", source: stitch.FSTileSource{FS: fstest.MapFS{}}, path: "/0/0/0.png", status: http.StatusNotFound},
+		{name: "missing file", source: stitch.FSTileSource{FS: fstest.MapFS{}}, path: "/1/0/0.png", status: http.StatusNotFound},
+		{
+			name: "missing upstream",
+			source: stitch.TileSourceFunc(func(_ context.Context, _ imagerequest.ResolvedParams) (image.Image, error) {
+				return nil, client.StatusError{URL: "https://example.com/iiif/image", StatusCode: http.StatusNotFound}
+			}),
+			path:   "/1/0/0.png",
+			status: http.StatusNotFound,
+		},
+		{
+			name: "failed upstream",
+			source: stitch.TileSourceFunc(func(_ context.Context, _ imagerequest.ResolvedParams) (image.Image, error) {
+				return nil, client.StatusError{URL: "https://example.com/iiif/image", StatusCode: http.StatusServiceUnavailable}
+			}),
+			path:   "/1/0/0.png",
+			status: http.StatusBadGateway,
+		},
+	} {
+		r, err := NewXYZRenderer(info, XYZOptions{Source: tc.source})
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.name, err)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		if _e, _a := tc.status, w.Code; _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.name, _e, _a)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package pyramid
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// ZoomifyTilesPerGroup is the number of tiles in each `TileGroup{N}` directory.
+const ZoomifyTilesPerGroup = 256
+
+// ZoomifyImageProperties is the `ImageProperties.xml` document of a Zoomify image.
+type ZoomifyImageProperties struct {
+	XMLName   xml.Name `xml:"IMAGE_PROPERTIES"`
+	Width     uint32   `xml:"WIDTH,attr"`
+	Height    uint32   `xml:"HEIGHT,attr"`
+	NumTiles  int      `xml:"NUMTILES,attr"`
+	NumImages int      `xml:"NUMIMAGES,attr"`
+	Version   string   `xml:"VERSION,attr"`
+	TileSize  uint32   `xml:"TILESIZE,attr"`
+}
+
+// NewZoomifyImageProperties creates a descriptor for the tiles of info (see [NewZoomifyGrid]).
+func NewZoomifyImageProperties(info iiifimageapi.ImageInformation) (ZoomifyImageProperties, error) {
+	grid, err := NewZoomifyGrid(info)
+	if err != nil {
+		return ZoomifyImageProperties{}, err
+	}
+
+	return ZoomifyImageProperties{
+		Width:     grid.ImageSize[0],
+		Height:    grid.ImageSize[1],
+		NumTiles:  grid.NumTiles(),
+		NumImages: 1,
+		Version:   "1.8",
+		TileSize:  grid.TileSize,
+	}, nil
+}
+
+// ParseZoomifyImageProperties decodes an `ImageProperties.xml` document.
+func ParseZoomifyImageProperties(data []byte) (ZoomifyImageProperties, error) {
+	var p ZoomifyImageProperties
+
+	err := xml.Unmarshal(data, &p)
+	if err != nil {
+		return ZoomifyImageProperties{}, err
+	} else if p.TileSize == 0 || p.Width == 0 || p.Height == 0 {
+		return ZoomifyImageProperties{}, errors.New("tile size, width, and height must not be 0")
+	}
+
+	return p, nil
+}
+
+// MarshalDocument encodes the properties as an XML document.
+func (p ZoomifyImageProperties) MarshalDocument() ([]byte, error) {
+	data, err := xml.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Grid returns the tile grid described by the properties.
+func (p ZoomifyImageProperties) Grid() ZoomifyGrid {
+	return ZoomifyGrid{
+		ImageSize: [2]uint32{p.Width, p.Height},
+		TileSize:  p.TileSize,
+	}
+}
+
+// ZoomifyGrid is the pyramid of a Zoomify image. Tier 0 is the smallest reduction which fits in a single tile and the
+// highest tier is the full image. Tiles are always JPEG and have no overlap.
+type ZoomifyGrid struct {
+	ImageSize [2]uint32
+	TileSize  uint32
+}
+
+// NewZoomifyGrid uses the first square tile configuration of info. Zoomify does not support rectangular tiles, and
+// every tier is advertised by `ImageProperties.xml`, so the configuration must include the scale factor of every tier.
+func NewZoomifyGrid(info iiifimageapi.ImageInformation) (ZoomifyGrid, error) {
+	var missingErr error
+
+	for _, tile := range info.Tiles {
+		if tile.Width == 0 || (tile.Height != 0 && tile.Height != tile.Width) {
+			continue
+		}
+
+		grid := ZoomifyGrid{
+			ImageSize: [2]uint32{info.Width, info.Height},
+			TileSize:  tile.Width,
+		}
+
+		if tier, ok := grid.missingTier(tile.ScaleFactors); ok {
+			if missingErr == nil {
+				missingErr = fmt.Errorf("tile scale factors do not include %d (required by tier %d)", grid.TierScaleFactor(tier), tier)
+			}
+
+			continue
+		}
+
+		return grid, nil
+	}
+
+	if missingErr != nil {
+		return ZoomifyGrid{}, missingErr
+	}
+
+	return ZoomifyGrid{}, errors.New("image information does not describe square tiles")
+}
+
+// missingTier returns the first tier whose scale factor is not in scaleFactors.
+func (g ZoomifyGrid) missingTier(scaleFactors []uint32) (int, bool) {
+	for tier := g.Tiers() - 1; tier >= 0; tier-- {
+		scaleFactor := g.TierScaleFactor(tier)
+
+		var found bool
+
+		for _, v := range scaleFactors {
+			if v == scaleFactor {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return tier, true
+		}
+	}
+
+	return 0, false
+}
+
+// ImageInformationTile returns the equivalent tile configuration, with a scale factor for every tier.
+func (g ZoomifyGrid) ImageInformationTile() iiifimageapi.ImageInformationTile {
+	tile := iiifimageapi.ImageInformationTile{
+		Width: g.TileSize,
+	}
+
+	for tier := g.Tiers() - 1; tier >= 0; tier-- {
+		tile.ScaleFactors = append(tile.ScaleFactors, g.TierScaleFactor(tier))
+	}
+
+	return tile
+}
+
+// Tiers returns the number of tiers.
+func (g ZoomifyGrid) Tiers() int {
+	tiers := 1
+
+	for scaleFactor := uint32(1); ; scaleFactor *= 2 {
+		size := levelSize(g.ImageSize, scaleFactor)
+		if size[0] <= g.TileSize && size[1] <= g.TileSize {
+			return tiers
+		}
+
+		tiers++
+	}
+}
+
+// TierScaleFactor returns the reduction of tier compared to the full image.
+func (g ZoomifyGrid) TierScaleFactor(tier int) uint32 {
+	return 1 << uint(g.Tiers()-1-tier)
+}
+
+// TierSize returns the dimensions of the image at tier.
+func (g ZoomifyGrid) TierSize(tier int) [2]uint32 {
+	return levelSize(g.ImageSize, g.TierScaleFactor(tier))
+}
+
+// TierTiles returns the number of columns and rows of tier.
+func (g ZoomifyGrid) TierTiles(tier int) [2]uint32 {
+	size := g.TierSize(tier)
+
+	return [2]uint32{
+		(size[0] + g.TileSize - 1) / g.TileSize,
+		(size[1] + g.TileSize - 1) / g.TileSize,
+	}
+}
+
+// NumTiles returns the number of tiles of every tier.
+func (g ZoomifyGrid) NumTiles() int {
+	var count int
+
+	for tier := 0; tier < g.Tiers(); tier++ {
+		tiles := g.TierTiles(tier)
+		count += int(tiles[0]) * int(tiles[1])
+	}
+
+	return count
+}
+
+// ZoomifyTile identifies a single tile of a [ZoomifyGrid].
+type ZoomifyTile struct {
+	Tier   int
+	Column uint32
+	Row    uint32
+}
+
+// TileIndex returns the position of tile when every tile is ordered by tier, row, and then column.
+func (g ZoomifyGrid) TileIndex(tile ZoomifyTile) int {
+	var index int
+
+	for tier := 0; tier < tile.Tier; tier++ {
+		tiles := g.TierTiles(tier)
+		index += int(tiles[0]) * int(tiles[1])
+	}
+
+	return index + int(tile.Row)*int(g.TierTiles(tile.Tier)[0]) + int(tile.Column)
+}
+
+// TilePath returns the `TileGroup{N}/{tier}-{column}-{row}.jpg` path of tile.
+func (g ZoomifyGrid) TilePath(tile ZoomifyTile) string {
+	return fmt.Sprintf("TileGroup%d/%d-%d-%d.jpg", g.TileIndex(tile)/ZoomifyTilesPerGroup, tile.Tier, tile.Column, tile.Row)
+}
+
+// ParseTilePath parses a path of [ZoomifyGrid.TilePath] and verifies its tile group.
+func (g ZoomifyGrid) ParseTilePath(path string) (ZoomifyTile, error) {
+	pathSplit := strings.Split(path, "/")
+	if len(pathSplit) != 2 || !strings.HasPrefix(pathSplit[0], "TileGroup") || !strings.HasSuffix(pathSplit[1], ".jpg") {
+		return ZoomifyTile{}, errors.New("invalid path format (expecting `TileGroup{N}/{tier}-{column}-{row}.jpg`)")
+	}
+
+	group, err := strconv.Atoi(strings.TrimPrefix(pathSplit[0], "TileGroup"))
+	if err != nil {
+		return ZoomifyTile{}, fmt.Errorf("parsing tile group: %v", err)
+	}
+
+	positionSplit := strings.Split(strings.TrimSuffix(pathSplit[1], ".jpg"), "-")
+	if len(positionSplit) != 3 {
+		return ZoomifyTile{}, errors.New("invalid file format (expecting `{tier}-{column}-{row}.jpg`)")
+	}
+
+	var position [3]uint64
+
+	for fieldIdx, field := range positionSplit {
+		position[fieldIdx], err = strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return ZoomifyTile{}, fmt.Errorf("parsing position[%d]: %v", fieldIdx, err)
+		}
+	}
+
+	tile := ZoomifyTile{
+		Tier:   int(position[0]),
+		Column: uint32(position[1]),
+		Row:    uint32(position[2]),
+	}
+
+	if _, ok := g.TileValue(tile); !ok {
+		return ZoomifyTile{}, fmt.Errorf("tile (%s) does not exist", path)
+	} else if _e, _a := g.TileIndex(tile)/ZoomifyTilesPerGroup, group; _e != _a {
+		return ZoomifyTile{}, fmt.Errorf("tile group: expected %d but got %d", _e, _a)
+	}
+
+	return tile, nil
+}
+
+// TileValue returns the full image region and size of tile.
+func (g ZoomifyGrid) TileValue(tile ZoomifyTile) (pixelset.Value, bool) {
+	if tile.Tier < 0 || tile.Tier >= g.Tiers() {
+		return pixelset.Value{}, false
+	}
+
+	tiles := g.TierTiles(tile.Tier)
+	if tile.Column >= tiles[0] || tile.Row >= tiles[1] {
+		return pixelset.Value{}, false
+	}
+
+	return levelValue(g.ImageSize, g.TierScaleFactor(tile.Tier), [4]uint32{
+		tile.Column * g.TileSize,
+		tile.Row * g.TileSize,
+		g.TileSize,
+		g.TileSize,
+	})
+}
+
+// TileForValue finds the tile whose region and size is value.
+func (g ZoomifyGrid) TileForValue(value pixelset.Value) (ZoomifyTile, bool) {
+	for tier := g.Tiers() - 1; tier >= 0; tier-- {
+		scaledTileSize := g.TileSize * g.TierScaleFactor(tier)
+
+		tile := ZoomifyTile{
+			Tier:   tier,
+			Column: value.Region[0] / scaledTileSize,
+			Row:    value.Region[1] / scaledTileSize,
+		}
+
+		if actual, ok := g.TileValue(tile); ok && actual == value {
+			return tile, true
+		}
+	}
+
+	return ZoomifyTile{}, false
+}
+
+// Enumerate lists every tile of every tier, in the order of [ZoomifyGrid.TileIndex].
+func (g ZoomifyGrid) Enumerate() []ZoomifyTile {
+	var tiles []ZoomifyTile
+
+	for tier := 0; tier < g.Tiers(); tier++ {
+		count := g.TierTiles(tier)
+
+		for row := uint32(0); row < count[1]; row++ {
+			for column := uint32(0); column < count[0]; column++ {
+				tiles = append(tiles, ZoomifyTile{
+					Tier:   tier,
+					Column: column,
+					Row:    row,
+				})
+			}
+		}
+	}
+
+	return tiles
+}
+
+// Resolve translates a request for tile into the equivalent "jpg" image request.
+func (g ZoomifyGrid) Resolve(tile ZoomifyTile, opts imagerequest.ResolveOptions) (imagerequest.ResolvedParams, error) {
+	value, ok := g.TileValue(tile)
+	if !ok {
+		return imagerequest.ResolvedParams{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("tile (%d-%d-%d) does not exist", tile.Tier, tile.Column, tile.Row))
+	}
+
+	return imagerequest.NewParsedParamsFromPixelset(value, "default", "jpg").Resolve(opts)
+}
@@ -0,0 +1,127 @@
+package pyramid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+func TestZoomifyGrid_Tiers(t *testing.T) {
+	grid, err := NewZoomifyGrid(exampleImageInformation())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := 3, grid.Tiers(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 11, grid.NumTiles(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []uint32{1, 2, 4}, grid.ImageInformationTile().ScaleFactors; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	value, ok := grid.TileValue(ZoomifyTile{Tier: 1, Column: 1, Row: 0})
+	if !ok {
+		t.Fatal("expected tile to exist")
+	} else if _e, _a := (pixelset.Value{Region: [4]uint32{64, 0, 36, 60}, Size: [2]uint32{18, 30}}), value; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "TileGroup0/2-3-1.jpg", grid.TilePath(ZoomifyTile{Tier: 2, Column: 3, Row: 1}); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestNewZoomifyGrid_MissingScaleFactors(t *testing.T) {
+	info := exampleImageInformation()
+	info.Tiles[0].ScaleFactors = []uint32{1, 2}
+
+	_, err := NewZoomifyGrid(info)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	} else if _e, _a := "tile scale factors do not include 4 (required by tier 0)", err.Error(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _, err := NewZoomifyImageProperties(info); err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	info.Tiles = append(info.Tiles, exampleImageInformation().Tiles...)
+
+	grid, err := NewZoomifyGrid(info)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	for _, tile := range grid.Enumerate() {
+		_, err := grid.Resolve(tile, imagerequest.ResolveOptions{
+			ImageInformation: info,
+			DefaultQuality:   "color",
+		})
+		if err != nil {
+			t.Fatalf("%v: expected `nil` but got: %v", tile, err)
+		}
+	}
+}
+
+func TestZoomifyGrid_RoundTrip(t *testing.T) {
+	grid := ZoomifyGrid{ImageSize: [2]uint32{5000, 3000}, TileSize: 256}
+
+	var lastPath string
+
+	for _, tile := range grid.Enumerate() {
+		path := grid.TilePath(tile)
+		lastPath = path
+
+		parsed, err := grid.ParseTilePath(path)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", path, err)
+		} else if _e, _a := tile, parsed; _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+
+		value, _ := grid.TileValue(tile)
+
+		if actual, ok := grid.TileForValue(value); !ok || actual != tile {
+			t.Fatalf("%s: expected `%v` but got: %v", path, tile, actual)
+		}
+	}
+
+	if _e, _a := "TileGroup1/", lastPath; !strings.HasPrefix(_a, _e) {
+		t.Fatalf("expected `%v` to have prefix `%v`", _a, _e)
+	} else if _, err := grid.ParseTilePath(strings.Replace(lastPath, "TileGroup1", "TileGroup0", 1)); err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
+
+func TestZoomifyImageProperties(t *testing.T) {
+	properties, err := NewZoomifyImageProperties(exampleImageInformation())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	data, err := properties.MarshalDocument()
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := `<IMAGE_PROPERTIES WIDTH="100" HEIGHT="60" NUMTILES="11" NUMIMAGES="1" VERSION="1.8" TILESIZE="32"></IMAGE_PROPERTIES>`+"\n", string(data); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	parsed, err := ParseZoomifyImageProperties(data)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := properties.Grid(), parsed.Grid(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	resolved, err := parsed.Grid().Resolve(ZoomifyTile{Tier: 0}, imagerequest.ResolveOptions{
+		ImageInformation:    exampleImageInformation(),
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "full/25,15/0/default.jpg", resolved.Canonical().String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
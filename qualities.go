@@ -0,0 +1,240 @@
+package iiifimageapi
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"sync"
+)
+
+// QualityTransform converts the pixels of a rendered image to a quality.
+type QualityTransform interface {
+	Transform(img image.Image) image.Image
+}
+
+// QualityTransformFunc adapts a function to the [QualityTransform] interface.
+type QualityTransformFunc func(img image.Image) image.Image
+
+func (f QualityTransformFunc) Transform(img image.Image) image.Image {
+	return f(img)
+}
+
+// ColorTransform renders the image as-is.
+var ColorTransform = QualityTransformFunc(func(img image.Image) image.Image {
+	return img
+})
+
+// GrayTransform renders the image in grayscale.
+var GrayTransform = QualityTransformFunc(func(img image.Image) image.Image {
+	if gray, ok := img.(*image.Gray); ok {
+		return gray
+	}
+
+	out := image.NewGray(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return out
+})
+
+// BitonalTransform renders each pixel as either black or white.
+type BitonalTransform struct {
+	// Threshold is the luminance at or above which a pixel becomes white. If 0, 128 is used.
+	Threshold uint8
+
+	// Dither diffuses the error of each pixel to its neighbors (Floyd-Steinberg) rather than using a hard threshold.
+	Dither bool
+}
+
+var _ QualityTransform = BitonalTransform{}
+
+func (t BitonalTransform) Transform(img image.Image) image.Image {
+	threshold := int(t.Threshold)
+	if threshold == 0 {
+		threshold = 128
+	}
+
+	gray := GrayTransform(img).(*image.Gray)
+	bounds := gray.Bounds()
+	out := image.NewGray(bounds)
+
+	width := bounds.Dx()
+	current := make([]int, width+2)
+	next := make([]int, width+2)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for i := range next {
+			next[i] = 0
+		}
+
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := x - bounds.Min.X + 1
+			v := int(gray.GrayAt(x, y).Y)
+
+			if t.Dither {
+				v += current[i] / 16
+			}
+
+			var bit int
+
+			if v >= threshold {
+				bit = 255
+			}
+
+			out.SetGray(x, y, color.Gray{Y: uint8(bit)})
+
+			if t.Dither {
+				e := v - bit
+
+				current[i+1] += e * 7
+				next[i-1] += e * 3
+				next[i] += e * 5
+				next[i+1] += e
+			}
+		}
+
+		current, next = next, current
+	}
+
+	return out
+}
+
+// Quality associates a quality name with its transform.
+type Quality struct {
+	Name      string
+	Transform QualityTransform
+}
+
+// QualityRegistry is a set of qualities which may be rendered. It is safe for concurrent use.
+type QualityRegistry struct {
+	mu        sync.RWMutex
+	qualities map[string]Quality
+}
+
+// NewQualityRegistry creates a registry with the color, gray, and bitonal qualities.
+func NewQualityRegistry() *QualityRegistry {
+	return &QualityRegistry{
+		qualities: map[string]Quality{
+			"color":   {Name: "color", Transform: ColorTransform},
+			"gray":    {Name: "gray", Transform: GrayTransform},
+			"bitonal": {Name: "bitonal", Transform: BitonalTransform{}},
+		},
+	}
+}
+
+// DefaultQualities is a shared registry which starts with the color, gray, and bitonal qualities.
+var DefaultQualities = NewQualityRegistry()
+
+// Register adds a custom quality. An error is returned if a quality with the same name is already registered. The
+// "default" quality is reserved.
+func (r *QualityRegistry) Register(quality Quality) error {
+	if quality.Name == "" {
+		return errors.New("quality name must not be empty")
+	} else if quality.Name == "default" {
+		return errors.New("quality (default) is reserved")
+	} else if quality.Transform == nil {
+		return fmt.Errorf("quality (%s): transform must not be nil", quality.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, known := r.qualities[quality.Name]; known {
+		return fmt.Errorf("quality (%s) is already registered", quality.Name)
+	}
+
+	r.qualities[quality.Name] = quality
+
+	return nil
+}
+
+// SetTransform replaces the transform of a registered quality (e.g. to configure [BitonalTransform]).
+func (r *QualityRegistry) SetTransform(name string, transform QualityTransform) error {
+	if transform == nil {
+		return fmt.Errorf("quality (%s): transform must not be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	quality, known := r.qualities[name]
+	if !known {
+		return fmt.Errorf("quality (%s) is not registered", name)
+	}
+
+	quality.Transform = transform
+	r.qualities[name] = quality
+
+	return nil
+}
+
+// GetByName returns a registered quality.
+func (r *QualityRegistry) GetByName(name string) (Quality, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quality, known := r.qualities[name]
+
+	return quality, known
+}
+
+// Names returns the names of all registered qualities, sorted.
+func (r *QualityRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+
+	for name := range r.qualities {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Transform applies the transform of the named quality to img. The name must be a literal quality (not "default").
+func (r *QualityRegistry) Transform(name string, img image.Image) (image.Image, error) {
+	quality, known := r.GetByName(name)
+	if !known {
+		return nil, fmt.Errorf("quality (%s) is not registered", name)
+	}
+
+	return quality.Transform.Transform(img), nil
+}
+
+// DefaultQualityForColorModel suggests the quality to use for "default" based on the color model of a source image.
+// Grayscale models suggest "gray", black and white palettes suggest "bitonal", and everything else suggests "color".
+func DefaultQualityForColorModel(model color.Model) string {
+	switch model {
+	case color.GrayModel, color.Gray16Model:
+		return "gray"
+	}
+
+	palette, ok := model.(color.Palette)
+	if !ok {
+		return "color"
+	}
+
+	quality := "bitonal"
+
+	for _, c := range palette {
+		r, g, b, _ := c.RGBA()
+
+		if r != g || g != b {
+			return "color"
+		} else if r != 0 && r != 0xffff {
+			quality = "gray"
+		}
+	}
+
+	return quality
+}
+
+// DefaultQualityForImage suggests the quality to use for "default" based on the color model of img.
+func DefaultQualityForImage(img image.Image) string {
+	return DefaultQualityForColorModel(img.ColorModel())
+}
@@ -0,0 +1,122 @@
+package iiifimageapi
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func gradientImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 4))
+
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(x * 16), B: uint8(x * 16), A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestQualityRegistry_Gray(t *testing.T) {
+	out, err := NewQualityRegistry().Transform("gray", gradientImage())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := color.GrayModel, out.ColorModel(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.Gray{Y: 160}), out.(*image.Gray).GrayAt(10, 0); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestQualityRegistry_BitonalThreshold(t *testing.T) {
+	r := NewQualityRegistry()
+
+	err := r.SetTransform("bitonal", BitonalTransform{Threshold: 200})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	out, err := r.Transform("bitonal", gradientImage())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	gray := out.(*image.Gray)
+
+	if _e, _a := uint8(0), gray.GrayAt(12, 0).Y; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint8(255), gray.GrayAt(13, 0).Y; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestQualityRegistry_BitonalDither(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+
+	for i := range img.Pix {
+		img.Pix[i] = 128 + 64
+	}
+
+	out := BitonalTransform{Dither: true}.Transform(img).(*image.Gray)
+
+	var white int
+
+	for _, v := range out.Pix {
+		if v == 255 {
+			white++
+		} else if v != 0 {
+			t.Fatalf("expected bitonal value but got: %v", v)
+		}
+	}
+
+	// 75% gray should be roughly 75% white
+	if white < 2900 || white > 3250 {
+		t.Fatalf("expected roughly 3072 white pixels but got: %v", white)
+	}
+}
+
+func TestQualityRegistry_Register(t *testing.T) {
+	r := NewQualityRegistry()
+
+	err := r.Register(Quality{
+		Name: "redacted",
+		Transform: QualityTransformFunc(func(img image.Image) image.Image {
+			return image.NewGray(img.Bounds())
+		}),
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if err := r.Register(Quality{Name: "default", Transform: ColorTransform}); err == nil {
+		t.Fatal("expected error but got none")
+	} else if err := r.Register(Quality{Name: "gray", Transform: ColorTransform}); err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	if _, err := r.Transform("redacted", gradientImage()); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "bitonal,color,gray,redacted", strings.Join(r.Names(), ","); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestDefaultQualityForImage(t *testing.T) {
+	for _, tc := range []struct {
+		img      image.Image
+		expected string
+	}{
+		{gradientImage(), "color"},
+		{image.NewGray(image.Rect(0, 0, 1, 1)), "gray"},
+		{image.NewGray16(image.Rect(0, 0, 1, 1)), "gray"},
+		{image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black, color.White}), "bitonal"},
+		{image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black, color.Gray{Y: 128}}), "gray"},
+		{image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black, color.RGBA{R: 255, A: 255}}), "color"},
+	} {
+		if _e, _a := tc.expected, DefaultQualityForImage(tc.img); _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+	}
+}
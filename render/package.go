@@ -0,0 +1,3 @@
+// render offers a minimal, pure-Go image processor for [imagerequest.ResolvedParams]. It favors simplicity over speed
+// and is intended for static exports, tools, and tests rather than high-volume image servers.
+package render
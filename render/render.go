@@ -0,0 +1,104 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// Renderer applies [imagerequest.ResolvedParams] to a source image.
+type Renderer struct {
+	// Qualities is used to transform pixels for the requested quality. If nil, [iiifimageapi.DefaultQualities] will be
+	// used.
+	Qualities *iiifimageapi.QualityRegistry
+
+	// Formats is used to encode images. If nil, [iiifimageapi.DefaultFormats] will be used.
+	Formats *iiifimageapi.FormatRegistry
+}
+
+func (r Renderer) getQualities() *iiifimageapi.QualityRegistry {
+	if r.Qualities != nil {
+		return r.Qualities
+	}
+
+	return iiifimageapi.DefaultQualities
+}
+
+func (r Renderer) getFormats() *iiifimageapi.FormatRegistry {
+	if r.Formats != nil {
+		return r.Formats
+	}
+
+	return iiifimageapi.DefaultFormats
+}
+
+// Render crops, scales, mirrors, rotates, and transforms src according to params. The src should be the full image
+// which params were resolved against; use [ToRGBA] once if the same source is rendered many times.
+func (r Renderer) Render(src image.Image, params imagerequest.ResolvedParams) (image.Image, error) {
+	region := params.RegionPixels()
+
+	rgba := ToRGBA(src)
+	bounds := rgba.Bounds()
+
+	cropRect := image.Rect(
+		bounds.Min.X+int(region[0]),
+		bounds.Min.Y+int(region[1]),
+		bounds.Min.X+int(region[0]+region[2]),
+		bounds.Min.Y+int(region[1]+region[3]),
+	)
+
+	if !cropRect.In(bounds) {
+		return nil, fmt.Errorf("region (%v) exceeds image bounds (%v)", region, bounds.Size())
+	}
+
+	return r.RenderRegion(rgba.SubImage(cropRect), params)
+}
+
+// RenderRegion scales, mirrors, rotates, and transforms region according to params. Unlike [Renderer.Render], region
+// must already be cropped to the region of params, but it may be any resolution (e.g. stitched from reduced tiles).
+func (r Renderer) RenderRegion(region image.Image, params imagerequest.ResolvedParams) (image.Image, error) {
+	size := params.SizePixels()
+
+	if size[0] == 0 || size[1] == 0 {
+		return nil, errors.New("size must not be empty")
+	}
+
+	img := Scale(ToRGBA(region), int(size[0]), int(size[1]))
+
+	if params.RotationIsMirrored() {
+		img = Mirror(img)
+	}
+
+	if params.RotationAmount() != 0 {
+		img = Rotate(img, float64(params.RotationAmount()))
+	}
+
+	return r.getQualities().Transform(params.Quality(), img)
+}
+
+// RenderTo renders src and encodes it to w with the format of params.
+func (r Renderer) RenderTo(w io.Writer, src image.Image, params imagerequest.ResolvedParams) error {
+	img, err := r.Render(src, params)
+	if err != nil {
+		return err
+	}
+
+	return r.getFormats().Encode(w, params.Format(), img)
+}
+
+// ToRGBA returns img as an [image.RGBA], converting it if necessary.
+func ToRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return out
+}
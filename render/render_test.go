@@ -0,0 +1,114 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// quadrantImage is 40x20 with red, green, blue, and white 20x10 quadrants (clockwise from top-left).
+func quadrantImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			var c color.RGBA
+
+			switch {
+			case x < 20 && y < 10:
+				c = color.RGBA{R: 255, A: 255}
+			case y < 10:
+				c = color.RGBA{G: 255, A: 255}
+			case x >= 20:
+				c = color.RGBA{B: 255, A: 255}
+			default:
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func mustResolve(raw imagerequest.RawParams) imagerequest.ResolvedParams {
+	parsed, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	resolved, err := parsed.Resolve(imagerequest.ResolveOptions{
+		ImageInformation: iiifimageapi.ImageInformation{
+			Profile: iiifimageapi.ComplianceLevel2Name,
+			Width:   40,
+			Height:  20,
+		},
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return resolved
+}
+
+func TestRenderer_Render_RegionSize(t *testing.T) {
+	img, err := Renderer{}.Render(quadrantImage(), mustResolve(imagerequest.RawParams{"20,0,20,20", "10,10", "0", "default.png"}))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := image.Pt(10, 10), img.Bounds().Size(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.RGBA{G: 255, A: 255}), img.At(0, 0); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.RGBA{B: 255, A: 255}), img.At(9, 9); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestRenderer_Render_MirrorRotate(t *testing.T) {
+	img, err := Renderer{}.Render(quadrantImage(), mustResolve(imagerequest.RawParams{"full", "max", "!90", "default.png"}))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := image.Pt(20, 40), img.Bounds().Size(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// mirrored: green top-left, blue bottom-left; then rotated 90 clockwise: blue top-left, green top-right
+	if _e, _a := (color.RGBA{B: 255, A: 255}), img.At(0, 0); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.RGBA{G: 255, A: 255}), img.At(19, 0); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (color.RGBA{R: 255, G: 255, B: 255, A: 255}), img.At(0, 39); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestRenderer_Render_Gray(t *testing.T) {
+	img, err := Renderer{}.Render(quadrantImage(), mustResolve(imagerequest.RawParams{"full", "max", "0", "gray.png"}))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := color.GrayModel, img.ColorModel(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestScale_Average(t *testing.T) {
+	img := Scale(quadrantImage(), 2, 1)
+
+	// left half averages red and white
+	if _e, _a := (color.RGBA{R: 255, G: 128, B: 128, A: 255}), img.RGBAAt(0, 0); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestRotatedSize(t *testing.T) {
+	w, h := RotatedSize(300, 200, 22.5)
+	if _e, _a := [2]int{354, 300}, [2]int{w, h}; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
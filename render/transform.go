@@ -0,0 +1,196 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+// Scale resizes src to exactly w by h pixels by averaging the area of source pixels covered by each output pixel.
+func Scale(src *image.RGBA, w, h int) *image.RGBA {
+	bounds := src.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	if bounds.Dx() == w && bounds.Dy() == h {
+		for y := 0; y < h; y++ {
+			copy(out.Pix[y*out.Stride:y*out.Stride+w*4], src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y+y):])
+		}
+
+		return out
+	}
+
+	xSpans := scaleSpans(bounds.Dx(), w)
+	ySpans := scaleSpans(bounds.Dy(), h)
+
+	for oy, ySpan := range ySpans {
+		for ox, xSpan := range xSpans {
+			var sum [4]float64
+			var total float64
+
+			for iy := ySpan.from; iy < ySpan.to; iy++ {
+				wy := ySpan.weight(iy)
+
+				for ix := xSpan.from; ix < xSpan.to; ix++ {
+					weight := wy * xSpan.weight(ix)
+					i := src.PixOffset(bounds.Min.X+ix, bounds.Min.Y+iy)
+
+					sum[0] += float64(src.Pix[i]) * weight
+					sum[1] += float64(src.Pix[i+1]) * weight
+					sum[2] += float64(src.Pix[i+2]) * weight
+					sum[3] += float64(src.Pix[i+3]) * weight
+					total += weight
+				}
+			}
+
+			o := out.PixOffset(ox, oy)
+
+			for c := 0; c < 4; c++ {
+				out.Pix[o+c] = uint8(math.Round(sum[c] / total))
+			}
+		}
+	}
+
+	return out
+}
+
+type scaleSpan struct {
+	start, end float64
+	from, to   int
+}
+
+func (s scaleSpan) weight(i int) float64 {
+	return math.Min(s.end, float64(i+1)) - math.Max(s.start, float64(i))
+}
+
+func scaleSpans(in, out int) []scaleSpan {
+	ratio := float64(in) / float64(out)
+	spans := make([]scaleSpan, out)
+
+	for o := range spans {
+		start := float64(o) * ratio
+		end := math.Min(float64(in), start+ratio)
+
+		spans[o] = scaleSpan{
+			start: start,
+			end:   end,
+			from:  int(math.Floor(start)),
+			to:    int(math.Ceil(end)),
+		}
+	}
+
+	return spans
+}
+
+// Mirror flips src across its vertical axis.
+func Mirror(src *image.RGBA) *image.RGBA {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			copy(out.Pix[out.PixOffset(w-1-x, y):][:4], src.Pix[src.PixOffset(bounds.Min.X+x, bounds.Min.Y+y):][:4])
+		}
+	}
+
+	return out
+}
+
+// RotatedSize returns the size of the bounding box after rotating w by h clockwise by degrees.
+func RotatedSize(w, h int, degrees float64) (int, int) {
+	switch degrees {
+	case 0, 180:
+		return w, h
+	case 90, 270:
+		return h, w
+	}
+
+	rad := degrees * math.Pi / 180
+	sin, cos := math.Abs(math.Sin(rad)), math.Abs(math.Cos(rad))
+
+	return int(math.Round(float64(w)*cos + float64(h)*sin)), int(math.Round(float64(w)*sin + float64(h)*cos))
+}
+
+// Rotate turns src clockwise by degrees. Multiples of 90 are exact; other angles are sampled bilinearly into the
+// bounding box of the result, leaving uncovered pixels transparent.
+func Rotate(src *image.RGBA, degrees float64) *image.RGBA {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	ow, oh := RotatedSize(w, h, degrees)
+	out := image.NewRGBA(image.Rect(0, 0, ow, oh))
+
+	var mapping func(ox, oy int) (int, int)
+
+	switch degrees {
+	case 90:
+		mapping = func(ox, oy int) (int, int) { return oy, h - 1 - ox }
+	case 180:
+		mapping = func(ox, oy int) (int, int) { return w - 1 - ox, h - 1 - oy }
+	case 270:
+		mapping = func(ox, oy int) (int, int) { return w - 1 - oy, ox }
+	}
+
+	if mapping != nil {
+		for oy := 0; oy < oh; oy++ {
+			for ox := 0; ox < ow; ox++ {
+				ix, iy := mapping(ox, oy)
+
+				copy(out.Pix[out.PixOffset(ox, oy):][:4], src.Pix[src.PixOffset(bounds.Min.X+ix, bounds.Min.Y+iy):][:4])
+			}
+		}
+
+		return out
+	}
+
+	rad := degrees * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+
+	for oy := 0; oy < oh; oy++ {
+		for ox := 0; ox < ow; ox++ {
+			// pixel center relative to the output center, rotated back counter-clockwise
+			dx := float64(ox) + 0.5 - float64(ow)/2
+			dy := float64(oy) + 0.5 - float64(oh)/2
+
+			sx := dx*cos + dy*sin + float64(w)/2 - 0.5
+			sy := -dx*sin + dy*cos + float64(h)/2 - 0.5
+
+			sampleBilinear(src, sx, sy, out.Pix[out.PixOffset(ox, oy):][:4])
+		}
+	}
+
+	return out
+}
+
+func sampleBilinear(src *image.RGBA, x, y float64, dst []uint8) {
+	bounds := src.Bounds()
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	var sum [4]float64
+
+	for _, corner := range [4]struct {
+		dx, dy int
+		weight float64
+	}{
+		{0, 0, (1 - fx) * (1 - fy)},
+		{1, 0, fx * (1 - fy)},
+		{0, 1, (1 - fx) * fy},
+		{1, 1, fx * fy},
+	} {
+		px, py := x0+corner.dx, y0+corner.dy
+
+		if px < 0 || py < 0 || px >= bounds.Dx() || py >= bounds.Dy() {
+			// outside contributes transparency
+			continue
+		}
+
+		i := src.PixOffset(bounds.Min.X+px, bounds.Min.Y+py)
+
+		for c := 0; c < 4; c++ {
+			sum[c] += float64(src.Pix[i+c]) * corner.weight
+		}
+	}
+
+	for c := 0; c < 4; c++ {
+		dst[c] = uint8(math.Round(sum[c]))
+	}
+}
@@ -0,0 +1,3 @@
+// responsive offers functions to generate `srcset`, `sizes`, and `<picture>` markup for an image service, using only
+// canonical image requests which the profile of the service supports.
+package responsive
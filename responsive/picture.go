@@ -0,0 +1,193 @@
+package responsive
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+// PictureOptions configures [NewPicture].
+type PictureOptions struct {
+	// Widths are the breakpoint widths of the srcset candidates.
+	Widths []uint32
+
+	// Sizes is the value of the `sizes` attribute (e.g. `(max-width: 600px) 100vw, 600px`). If empty, the attribute is
+	// omitted.
+	Sizes string
+
+	// Alt is the alternate text of the image.
+	Alt string
+
+	// FallbackFormat is the format of the `<img>` element, which should be supported by every browser. If empty, "jpg"
+	// is used.
+	FallbackFormat string
+
+	// NoSources may be set to true to only generate the `<img>` element without `<source>` elements for the other
+	// preferred formats of the image.
+	NoSources bool
+
+	// Formats is used to find the media type of each source. If nil, the default formats are used.
+	Formats *iiifimageapi.FormatRegistry
+}
+
+func (o PictureOptions) getFallbackFormat() string {
+	if o.FallbackFormat != "" {
+		return o.FallbackFormat
+	}
+
+	return "jpg"
+}
+
+func (o PictureOptions) getFormats() *iiifimageapi.FormatRegistry {
+	if o.Formats != nil {
+		return o.Formats
+	}
+
+	return iiifimageapi.DefaultFormats
+}
+
+// Source is a `<source>` element of a `<picture>`.
+type Source struct {
+	Format string
+	Type   string
+	Srcset Srcset
+	Sizes  string
+}
+
+// HTML returns the `<source>` element.
+func (s Source) HTML() string {
+	b := &strings.Builder{}
+
+	b.WriteString("<source")
+	writeAttr(b, "type", s.Type)
+	writeAttr(b, "srcset", s.Srcset.String())
+
+	if s.Sizes != "" {
+		writeAttr(b, "sizes", s.Sizes)
+	}
+
+	b.WriteString(">")
+
+	return b.String()
+}
+
+// Img is an `<img>` element.
+type Img struct {
+	Src    string
+	Srcset Srcset
+	Sizes  string
+	Width  uint32
+	Height uint32
+	Alt    string
+}
+
+// HTML returns the `<img>` element.
+func (i Img) HTML() string {
+	b := &strings.Builder{}
+
+	b.WriteString("<img")
+	writeAttr(b, "src", i.Src)
+
+	if len(i.Srcset) > 0 {
+		writeAttr(b, "srcset", i.Srcset.String())
+	}
+
+	if i.Sizes != "" {
+		writeAttr(b, "sizes", i.Sizes)
+	}
+
+	writeAttr(b, "width", fmt.Sprintf("%d", i.Width))
+	writeAttr(b, "height", fmt.Sprintf("%d", i.Height))
+	writeAttr(b, "alt", i.Alt)
+	b.WriteString(">")
+
+	return b.String()
+}
+
+// Picture is a `<picture>` element. Sources are ordered by the preference of the image and the fallback format is
+// only used by Img.
+type Picture struct {
+	Sources []Source
+	Img     Img
+}
+
+// NewPicture creates the candidates of info (see [NewSrcset]) for each of its preferred formats and the fallback
+// format. The width and height of Img are those of its largest candidate so browsers may reserve the aspect ratio.
+func NewPicture(info iiifimageapi.ImageInformation, opts PictureOptions) (Picture, error) {
+	fallbackSrcset, err := NewSrcset(info, opts.Widths, opts.getFallbackFormat())
+	if err != nil {
+		return Picture{}, fmt.Errorf("format %s: %v", opts.getFallbackFormat(), err)
+	}
+
+	largest, _ := fallbackSrcset.Largest()
+
+	picture := Picture{
+		Img: Img{
+			Src:    largest.URL,
+			Srcset: fallbackSrcset,
+			Sizes:  opts.Sizes,
+			Width:  largest.Width,
+			Height: largest.Height,
+			Alt:    opts.Alt,
+		},
+	}
+
+	if opts.NoSources {
+		return picture, nil
+	}
+
+	for _, format := range info.PreferredFormats {
+		if format == opts.getFallbackFormat() {
+			continue
+		}
+
+		formatInfo, ok := opts.getFormats().GetByName(format)
+		if !ok {
+			return Picture{}, fmt.Errorf("format %s: not registered", format)
+		}
+
+		srcset, err := NewSrcset(info, opts.Widths, format)
+		if err != nil {
+			return Picture{}, fmt.Errorf("format %s: %v", format, err)
+		}
+
+		picture.Sources = append(picture.Sources, Source{
+			Format: format,
+			Type:   formatInfo.MediaType,
+			Srcset: srcset,
+			Sizes:  opts.Sizes,
+		})
+	}
+
+	return picture, nil
+}
+
+// HTML returns the `<picture>` element, or only the `<img>` element if there are no sources.
+func (p Picture) HTML() string {
+	if len(p.Sources) == 0 {
+		return p.Img.HTML()
+	}
+
+	b := &strings.Builder{}
+
+	b.WriteString("<picture>")
+
+	for _, source := range p.Sources {
+		b.WriteString(source.HTML())
+	}
+
+	b.WriteString(p.Img.HTML())
+	b.WriteString("</picture>")
+
+	return b.String()
+}
+
+func writeAttr(b *strings.Builder, name, value string) {
+	b.WriteString(" ")
+	b.WriteString(name)
+	b.WriteString(`="`)
+	b.WriteString(html.EscapeString(value))
+	b.WriteString(`"`)
+}
@@ -0,0 +1,122 @@
+package responsive
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// Candidate is a single entry of a `srcset` attribute.
+type Candidate struct {
+	// URL is the image request URL, which is canonical whenever the profile accepts it.
+	URL string
+
+	// Params are the parameters of URL.
+	Params imagerequest.ParsedParams
+
+	// Width and Height are the actual dimensions of the image. Width is used as the `w` descriptor.
+	Width  uint32
+	Height uint32
+}
+
+// Srcset is a list of candidates, ordered by width.
+type Srcset []Candidate
+
+// String returns the value of a `srcset` attribute (e.g. `https://example.com/image/full/300,200/0/default.jpg 300w`).
+func (s Srcset) String() string {
+	entries := make([]string, len(s))
+
+	for idx, candidate := range s {
+		entries[idx] = fmt.Sprintf("%s %dw", candidate.URL, candidate.Width)
+	}
+
+	return strings.Join(entries, ", ")
+}
+
+// Largest returns the widest candidate, or false if there are none.
+func (s Srcset) Largest() (Candidate, bool) {
+	if len(s) == 0 {
+		return Candidate{}, false
+	}
+
+	return s[len(s)-1], true
+}
+
+// NewSrcset creates the candidates of info for each of widths in format. Each width is resolved with
+// [imagerequest.BestSize] using the aspect ratio of the image, so level0 images use their nearest advertised size and
+// other profiles use exact sizes. Each URL is canonical unless the profile does not accept the canonical form. Widths
+// which resolve to the same image are only included once, and widths beyond the largest supported size are capped to
+// it. If format is empty, the first preferred format of info is used, otherwise "jpg".
+func NewSrcset(info iiifimageapi.ImageInformation, widths []uint32, format string) (Srcset, error) {
+	if info.Width == 0 || info.Height == 0 {
+		return nil, errors.New("image width and height must not be 0")
+	} else if len(widths) == 0 {
+		return nil, errors.New("widths must not be empty")
+	}
+
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   "color",
+	}
+
+	seen := map[string]struct{}{}
+
+	var srcset Srcset
+
+	for _, width := range widths {
+		if width == 0 {
+			return nil, errors.New("widths must be greater than 0")
+		}
+
+		// rounded up so the width, rather than the height, is what confines the size
+		height := uint32(math.Ceil(float64(info.Height) * float64(width) / float64(info.Width)))
+
+		params, err := imagerequest.BestSize(info, [2]uint32{width, height}, imagerequest.SizePolicyNearest)
+		if err != nil {
+			return nil, fmt.Errorf("width %d: %v", width, err)
+		}
+
+		if format != "" {
+			params.Format = format
+		}
+
+		resolved, err := params.Resolve(resolveOptions)
+		if err != nil {
+			return nil, fmt.Errorf("width %d: %v", width, err)
+		}
+
+		// level0 profiles may not accept the canonical form of max (e.g. w,h of an unadvertised size)
+		linked := resolved.Canonical()
+		if _, err := linked.Resolve(resolveOptions); err != nil {
+			linked = params
+		}
+
+		key := linked.String()
+
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		size := resolved.SizePixels()
+
+		srcset = append(srcset, Candidate{
+			URL:    strings.TrimSuffix(info.ID, "/") + "/" + key,
+			Params: linked,
+			Width:  size[0],
+			Height: size[1],
+		})
+	}
+
+	sort.SliceStable(srcset, func(i, j int) bool {
+		return srcset[i].Width < srcset[j].Width
+	})
+
+	return srcset, nil
+}
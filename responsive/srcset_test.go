@@ -0,0 +1,113 @@
+package responsive
+
+import (
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+func exampleLevel0ImageInformation() iiifimageapi.ImageInformation {
+	return iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      "https://example.com/iiif/image",
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   3000,
+		Height:  2000,
+		Sizes: []iiifimageapi.ImageInformationSize{
+			{Width: 375, Height: 250},
+			{Width: 750, Height: 500},
+			{Width: 1500, Height: 1000},
+		},
+	})
+}
+
+func exampleLevel2ImageInformation() iiifimageapi.ImageInformation {
+	return iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:               "https://example.com/iiif/image/",
+		Profile:          iiifimageapi.ComplianceLevel2Name,
+		Width:            3000,
+		Height:           2000,
+		PreferredFormats: []string{"webp", "jpg"},
+		ExtraFormats:     []string{"webp"},
+	})
+}
+
+func TestNewSrcset_Level0(t *testing.T) {
+	info := exampleLevel0ImageInformation()
+
+	srcset, err := NewSrcset(info, []uint32{320, 640, 800, 1600, 4000}, "")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := "https://example.com/iiif/image/full/375,250/0/default.jpg 375w, "+
+		"https://example.com/iiif/image/full/750,500/0/default.jpg 750w, "+
+		"https://example.com/iiif/image/full/1500,1000/0/default.jpg 1500w, "+
+		"https://example.com/iiif/image/full/max/0/default.jpg 3000w", srcset.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, candidate := range srcset {
+		_, err := candidate.Params.Resolve(imagerequest.ResolveOptions{
+			ImageInformation: info,
+			DefaultQuality:   "color",
+		})
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", candidate.URL, err)
+		}
+	}
+}
+
+func TestNewSrcset_Level2(t *testing.T) {
+	srcset, err := NewSrcset(exampleLevel2ImageInformation(), []uint32{640, 320}, "jpg")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := "https://example.com/iiif/image/full/320,213/0/default.jpg 320w, "+
+		"https://example.com/iiif/image/full/640,426/0/default.jpg 640w", srcset.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := uint32(213), srcset[0].Height; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	_, err = NewSrcset(exampleLevel2ImageInformation(), []uint32{640}, "gif")
+	if err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestNewPicture(t *testing.T) {
+	picture, err := NewPicture(exampleLevel2ImageInformation(), PictureOptions{
+		Widths: []uint32{320, 640},
+		Sizes:  "(max-width: 640px) 100vw, 640px",
+		Alt:    `A "quoted" <title>`,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := 1, len(picture.Sources); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "image/webp", picture.Sources[0].Type; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	expected := `<picture>` +
+		`<source type="image/webp" srcset="https://example.com/iiif/image/full/320,213/0/default.webp 320w, https://example.com/iiif/image/full/640,426/0/default.webp 640w" sizes="(max-width: 640px) 100vw, 640px">` +
+		`<img src="https://example.com/iiif/image/full/640,426/0/default.jpg" srcset="https://example.com/iiif/image/full/320,213/0/default.jpg 320w, https://example.com/iiif/image/full/640,426/0/default.jpg 640w" sizes="(max-width: 640px) 100vw, 640px" width="640" height="426" alt="A &#34;quoted&#34; &lt;title&gt;">` +
+		`</picture>`
+
+	if _e, _a := expected, picture.HTML(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	picture, err = NewPicture(exampleLevel0ImageInformation(), PictureOptions{
+		Widths: []uint32{400},
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := `<img src="https://example.com/iiif/image/full/375,250/0/default.jpg" srcset="https://example.com/iiif/image/full/375,250/0/default.jpg 375w" width="375" height="250" alt="">`, picture.HTML(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,219 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// MediaFragment is a spatial `xywh=` media fragment. Pixel values are whole numbers and percent values are relative to
+// the width and height of the image.
+type MediaFragment struct {
+	IsPercent bool
+	Values    [4]float64
+}
+
+// ParseMediaFragment parses an `xywh=` fragment (e.g. `xywh=160,120,320,240` or `xywh=percent:25,25,50,50`). A URI
+// with the fragment (e.g. a canvas or FragmentSelector target) is also accepted and other fragment parameters are
+// ignored.
+func ParseMediaFragment(s string) (MediaFragment, error) {
+	if idx := strings.Index(s, "#"); idx >= 0 {
+		s = s[idx+1:]
+	}
+
+	var value string
+	var found bool
+
+	for _, param := range strings.Split(s, "&") {
+		if strings.HasPrefix(param, "xywh=") {
+			value = strings.TrimPrefix(param, "xywh=")
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		return MediaFragment{}, errors.New("missing xywh parameter")
+	}
+
+	var f MediaFragment
+
+	if strings.HasPrefix(value, "percent:") {
+		f.IsPercent = true
+		value = strings.TrimPrefix(value, "percent:")
+	} else {
+		value = strings.TrimPrefix(value, "pixel:")
+	}
+
+	valueSplit := strings.Split(value, ",")
+	if len(valueSplit) != 4 {
+		return MediaFragment{}, errors.New("invalid xywh format (expecting `x,y,w,h`)")
+	}
+
+	for fieldIdx, field := range valueSplit {
+		var err error
+
+		if f.IsPercent {
+			f.Values[fieldIdx], err = strconv.ParseFloat(field, 64)
+		} else {
+			var v uint64
+
+			v, err = strconv.ParseUint(field, 10, 32)
+			f.Values[fieldIdx] = float64(v)
+		}
+
+		if err != nil {
+			return MediaFragment{}, fmt.Errorf("parsing xywh[%d]: %v", fieldIdx, err)
+		} else if f.Values[fieldIdx] < 0 || math.IsNaN(f.Values[fieldIdx]) || math.IsInf(f.Values[fieldIdx], 0) {
+			return MediaFragment{}, fmt.Errorf("parsing xywh[%d]: must be a non-negative number", fieldIdx)
+		}
+	}
+
+	if f.Values[2] == 0 || f.Values[3] == 0 {
+		return MediaFragment{}, errors.New("width and height must be greater than zero")
+	}
+
+	return f, nil
+}
+
+// NewPixelMediaFragment creates a pixel fragment which covers bounds of [X, Y, Width, Height], expanded to whole
+// pixels.
+func NewPixelMediaFragment(bounds [4]float64) MediaFragment {
+	x0, y0 := math.Max(math.Floor(bounds[0]), 0), math.Max(math.Floor(bounds[1]), 0)
+	x1, y1 := math.Ceil(bounds[0]+bounds[2]), math.Ceil(bounds[1]+bounds[3])
+
+	return MediaFragment{
+		Values: [4]float64{x0, y0, math.Max(x1-x0, 0), math.Max(y1-y0, 0)},
+	}
+}
+
+// NewMediaFragmentFromParsedParams uses the pixel or percent region of params. The full and square regions depend on
+// the image and must be resolved first (see [NewMediaFragmentFromResolvedParams]).
+func NewMediaFragmentFromParsedParams(params imagerequest.ParsedParams) (MediaFragment, error) {
+	if params.RegionIsEnum {
+		return MediaFragment{}, iiifimageapi.NewInvalidValueError(fmt.Sprintf("region (%s) has no fragment equivalent without resolving", params.RegionEnum))
+	} else if params.RegionIsPercent {
+		return MediaFragment{
+			IsPercent: true,
+			Values: [4]float64{
+				roundPercent(params.RegionPercent[0]),
+				roundPercent(params.RegionPercent[1]),
+				roundPercent(params.RegionPercent[2]),
+				roundPercent(params.RegionPercent[3]),
+			},
+		}, nil
+	}
+
+	return MediaFragment{
+		Values: [4]float64{
+			float64(params.RegionPixels[0]),
+			float64(params.RegionPixels[1]),
+			float64(params.RegionPixels[2]),
+			float64(params.RegionPixels[3]),
+		},
+	}, nil
+}
+
+// NewMediaFragmentFromResolvedParams uses the pixel region of params.
+func NewMediaFragmentFromResolvedParams(params imagerequest.ResolvedParams) MediaFragment {
+	region := params.RegionPixels()
+
+	return MediaFragment{
+		Values: [4]float64{float64(region[0]), float64(region[1]), float64(region[2]), float64(region[3])},
+	}
+}
+
+// String returns the fragment in the form of `xywh=...`.
+func (f MediaFragment) String() string {
+	var prefix string
+
+	if f.IsPercent {
+		prefix = "percent:"
+	}
+
+	return fmt.Sprintf(
+		"xywh=%s%s,%s,%s,%s",
+		prefix,
+		strconv.FormatFloat(f.Values[0], 'f', -1, 64),
+		strconv.FormatFloat(f.Values[1], 'f', -1, 64),
+		strconv.FormatFloat(f.Values[2], 'f', -1, 64),
+		strconv.FormatFloat(f.Values[3], 'f', -1, 64),
+	)
+}
+
+// ParsedParams returns a copy of base whose region is the fragment, as either a pixel or `pct:` region. Only the
+// region is changed, so base is typically the size, rotation, quality, and format desired for the crop (e.g. `max`,
+// `0`, and `default.jpg`).
+func (f MediaFragment) ParsedParams(base imagerequest.ParsedParams) imagerequest.ParsedParams {
+	params := base.Clone()
+	params.RegionIsEnum = false
+	params.RegionEnum = ""
+	params.RegionIsPercent = f.IsPercent
+	params.RegionPercent = [4]float32{}
+	params.RegionPixels = [4]uint32{}
+
+	if f.IsPercent {
+		params.RegionPercent = [4]float32{float32(f.Values[0]), float32(f.Values[1]), float32(f.Values[2]), float32(f.Values[3])}
+	} else {
+		px := NewPixelMediaFragment(f.Values)
+		params.RegionPixels = [4]uint32{uint32(px.Values[0]), uint32(px.Values[1]), uint32(px.Values[2]), uint32(px.Values[3])}
+	}
+
+	return params
+}
+
+// Bounds returns the fragment as [X, Y, Width, Height] in the pixels of an image of size.
+func (f MediaFragment) Bounds(size [2]uint32) [4]float64 {
+	if !f.IsPercent {
+		return f.Values
+	}
+
+	return [4]float64{
+		f.Values[0] * float64(size[0]) / 100,
+		f.Values[1] * float64(size[1]) / 100,
+		f.Values[2] * float64(size[0]) / 100,
+		f.Values[3] * float64(size[1]) / 100,
+	}
+}
+
+// Scale converts a pixel fragment from the coordinates of an image of size from to one of size to, expanded to whole
+// pixels. Percent fragments are independent of size and are returned as-is.
+func (f MediaFragment) Scale(from, to [2]uint32) MediaFragment {
+	if f.IsPercent || from == to {
+		return f
+	}
+
+	return NewPixelMediaFragment(ScaleBounds(f.Values, from, to))
+}
+
+// CanvasToImage converts a fragment of a Presentation canvas of canvasSize to the pixels of info. The canvas and image
+// often differ when the canvas describes the physical object or a different resolution.
+func CanvasToImage(f MediaFragment, canvasSize [2]uint32, info iiifimageapi.ImageInformation) MediaFragment {
+	return f.Scale(canvasSize, [2]uint32{info.Width, info.Height})
+}
+
+// ImageToCanvas converts a fragment of the pixels of info to a Presentation canvas of canvasSize.
+func ImageToCanvas(f MediaFragment, info iiifimageapi.ImageInformation, canvasSize [2]uint32) MediaFragment {
+	return f.Scale([2]uint32{info.Width, info.Height}, canvasSize)
+}
+
+// ScaleBounds converts [X, Y, Width, Height] from the coordinates of size from to size to.
+func ScaleBounds(bounds [4]float64, from, to [2]uint32) [4]float64 {
+	sx := float64(to[0]) / float64(from[0])
+	sy := float64(to[1]) / float64(from[1])
+
+	return [4]float64{bounds[0] * sx, bounds[1] * sy, bounds[2] * sx, bounds[3] * sy}
+}
+
+// roundPercent drops the float32 noise of parsed percents (e.g. 33.33 rather than 33.33000183105469).
+func roundPercent(v float32) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+
+	return f
+}
@@ -0,0 +1,137 @@
+package selector
+
+import (
+	"testing"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+func mustParseParams(s string) imagerequest.ParsedParams {
+	raw, err := imagerequest.RawParamsFromString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	params, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return params
+}
+
+func TestParseMediaFragment(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected MediaFragment
+		str      string
+	}{
+		{
+			in:       "xywh=160,120,320,240",
+			expected: MediaFragment{Values: [4]float64{160, 120, 320, 240}},
+			str:      "xywh=160,120,320,240",
+		},
+		{
+			in:       "https://example.com/canvas/1#t=10&xywh=pixel:0,0,10,20",
+			expected: MediaFragment{Values: [4]float64{0, 0, 10, 20}},
+			str:      "xywh=0,0,10,20",
+		},
+		{
+			in:       "#xywh=percent:25,12.5,50,50",
+			expected: MediaFragment{IsPercent: true, Values: [4]float64{25, 12.5, 50, 50}},
+			str:      "xywh=percent:25,12.5,50,50",
+		},
+	} {
+		f, err := ParseMediaFragment(tc.in)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.in, err)
+		} else if _e, _a := tc.expected, f; _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.in, _e, _a)
+		} else if _e, _a := tc.str, f.String(); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.in, _e, _a)
+		}
+	}
+
+	for _, in := range []string{"t=10", "xywh=1,2,3", "xywh=1.5,2,3,4", "xywh=-1,2,3,4", "xywh=0,0,0,10", "xywh=percent:a,1,1,1"} {
+		if _, err := ParseMediaFragment(in); err == nil {
+			t.Fatalf("%s: expected error", in)
+		}
+	}
+}
+
+func TestMediaFragment_ParsedParams(t *testing.T) {
+	base := mustParseParams("full/max/0/default.jpg")
+
+	f, _ := ParseMediaFragment("xywh=160,120,320,240")
+	if _e, _a := "160,120,320,240/max/0/default.jpg", f.ParsedParams(base).String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "full/max/0/default.jpg", base.String(); _e != _a {
+		t.Fatalf("expected base to be unchanged but got: %v", _a)
+	}
+
+	f, _ = ParseMediaFragment("xywh=percent:25,12.5,50,50")
+	if _e, _a := "pct:25,12.5,50,50/max/0/default.jpg", f.ParsedParams(base).String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestNewMediaFragmentFromParsedParams(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		out string
+	}{
+		{in: "160,120,320,240/max/0/default.jpg", out: "xywh=160,120,320,240"},
+		{in: "pct:33.33,0,50,100/max/0/default.jpg", out: "xywh=percent:33.33,0,50,100"},
+	} {
+		f, err := NewMediaFragmentFromParsedParams(mustParseParams(tc.in))
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.in, err)
+		} else if _e, _a := tc.out, f.String(); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.in, _e, _a)
+		}
+	}
+
+	_, err := NewMediaFragmentFromParsedParams(mustParseParams("square/max/0/default.jpg"))
+	if err == nil {
+		t.Fatal("expected error for unresolved region")
+	}
+
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		Profile: iiifimageapi.ComplianceLevel2Name,
+		Width:   300,
+		Height:  200,
+	})
+
+	resolved, err := mustParseParams("square/max/0/default.jpg").Resolve(imagerequest.ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   "color",
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "xywh=50,0,200,200", NewMediaFragmentFromResolvedParams(resolved).String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestCanvasToImage(t *testing.T) {
+	info := iiifimageapi.ImageInformation{Width: 3000, Height: 2000}
+
+	f, _ := ParseMediaFragment("xywh=100,50,33,20")
+
+	scaled := CanvasToImage(f, [2]uint32{1000, 667}, info)
+	if _e, _a := "xywh=300,149,99,61", scaled.String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _e, _a := "xywh=100,49,33,22", ImageToCanvas(scaled, info, [2]uint32{1000, 667}).String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	pct, _ := ParseMediaFragment("xywh=percent:10,10,50,50")
+	if _e, _a := pct, CanvasToImage(pct, [2]uint32{1000, 667}, info); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := [4]float64{300, 200, 1500, 1000}, pct.Bounds([2]uint32{info.Width, info.Height}); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,3 @@
+// selector offers conversions between Web Annotation selectors, such as `xywh=` media fragments and SVG selectors, and
+// the regions of image requests, including scaling between the coordinates of a Presentation canvas and its image.
+package selector
@@ -0,0 +1,274 @@
+package selector
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SvgBounds returns the bounding box of the shapes of an SvgSelector value as [X, Y, Width, Height]. The polygon,
+// polyline, rect, circle, ellipse, line, and path elements are supported. Curves of paths are bounded by their control
+// points, so the result may be slightly larger than the drawn shape. Transforms are not supported.
+func SvgBounds(svg string) ([4]float64, error) {
+	var b bounds
+
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return [4]float64{}, fmt.Errorf("decoding svg: %v", err)
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		attrs := map[string]string{}
+
+		for _, attr := range element.Attr {
+			if attr.Name.Local == "transform" {
+				return [4]float64{}, errors.New("transform attributes are not supported")
+			}
+
+			attrs[attr.Name.Local] = attr.Value
+		}
+
+		switch element.Name.Local {
+		case "polygon", "polyline":
+			numbers, err := parseSvgNumbers(attrs["points"])
+			if err != nil {
+				return [4]float64{}, fmt.Errorf("parsing %s points: %v", element.Name.Local, err)
+			} else if len(numbers) < 2 || len(numbers)%2 != 0 {
+				return [4]float64{}, fmt.Errorf("parsing %s points: expected pairs of coordinates", element.Name.Local)
+			}
+
+			for idx := 0; idx < len(numbers); idx += 2 {
+				b.add(numbers[idx], numbers[idx+1])
+			}
+		case "rect":
+			v, err := parseSvgAttrs(attrs, "x", "y", "width", "height")
+			if err != nil {
+				return [4]float64{}, fmt.Errorf("parsing rect: %v", err)
+			}
+
+			b.add(v[0], v[1])
+			b.add(v[0]+v[2], v[1]+v[3])
+		case "circle":
+			v, err := parseSvgAttrs(attrs, "cx", "cy", "r")
+			if err != nil {
+				return [4]float64{}, fmt.Errorf("parsing circle: %v", err)
+			}
+
+			b.add(v[0]-v[2], v[1]-v[2])
+			b.add(v[0]+v[2], v[1]+v[2])
+		case "ellipse":
+			v, err := parseSvgAttrs(attrs, "cx", "cy", "rx", "ry")
+			if err != nil {
+				return [4]float64{}, fmt.Errorf("parsing ellipse: %v", err)
+			}
+
+			b.add(v[0]-v[2], v[1]-v[3])
+			b.add(v[0]+v[2], v[1]+v[3])
+		case "line":
+			v, err := parseSvgAttrs(attrs, "x1", "y1", "x2", "y2")
+			if err != nil {
+				return [4]float64{}, fmt.Errorf("parsing line: %v", err)
+			}
+
+			b.add(v[0], v[1])
+			b.add(v[2], v[3])
+		case "path":
+			err := addSvgPath(&b, attrs["d"])
+			if err != nil {
+				return [4]float64{}, fmt.Errorf("parsing path: %v", err)
+			}
+		}
+	}
+
+	if !b.ok {
+		return [4]float64{}, errors.New("svg does not contain a supported shape")
+	}
+
+	return [4]float64{b.min[0], b.min[1], b.max[0] - b.min[0], b.max[1] - b.min[1]}, nil
+}
+
+type bounds struct {
+	ok  bool
+	min [2]float64
+	max [2]float64
+}
+
+func (b *bounds) add(x, y float64) {
+	if !b.ok {
+		b.ok = true
+		b.min = [2]float64{x, y}
+		b.max = [2]float64{x, y}
+
+		return
+	}
+
+	b.min = [2]float64{math.Min(b.min[0], x), math.Min(b.min[1], y)}
+	b.max = [2]float64{math.Max(b.max[0], x), math.Max(b.max[1], y)}
+}
+
+// svgRequiredAttrs are the dimensions which have no default.
+var svgRequiredAttrs = map[string]bool{"width": true, "height": true, "r": true, "rx": true, "ry": true}
+
+func parseSvgAttrs(attrs map[string]string, names ...string) ([]float64, error) {
+	values := make([]float64, len(names))
+
+	for idx, name := range names {
+		raw, ok := attrs[name]
+		if !ok {
+			// omitted positions default to zero
+			if !svgRequiredAttrs[name] {
+				continue
+			}
+
+			return nil, fmt.Errorf("missing %s", name)
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(raw), "px"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %v", name, err)
+		}
+
+		values[idx] = v
+	}
+
+	return values, nil
+}
+
+// parseSvgNumbers splits numbers by commas and whitespace, or by a sign in compact forms such as `10-5`.
+func parseSvgNumbers(s string) ([]float64, error) {
+	var numbers []float64
+
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		for start := 0; start < len(field); {
+			end := start + 1
+			for end < len(field) && !((field[end] == '-' || field[end] == '+') && field[end-1] != 'e' && field[end-1] != 'E') {
+				end++
+			}
+
+			v, err := strconv.ParseFloat(field[start:end], 64)
+			if err != nil {
+				return nil, err
+			}
+
+			numbers = append(numbers, v)
+			start = end
+		}
+	}
+
+	return numbers, nil
+}
+
+// svgPathArgs is the number of arguments of each path command.
+var svgPathArgs = map[byte]int{
+	'M': 2, 'L': 2, 'T': 2,
+	'H': 1, 'V': 1,
+	'C': 6, 'S': 4, 'Q': 4,
+	'Z': 0,
+}
+
+func isSvgPathCommand(c byte) bool {
+	return ((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) && c != 'e' && c != 'E'
+}
+
+func addSvgPath(b *bounds, d string) error {
+	var x, y, startX, startY float64
+
+	for idx := 0; idx < len(d); {
+		c := d[idx]
+		if c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			idx++
+
+			continue
+		}
+
+		upper := c &^ 0x20
+		argc, ok := svgPathArgs[upper]
+		if !ok {
+			return fmt.Errorf("unsupported command (%c)", c)
+		}
+
+		relative := c != upper
+		idx++
+
+		end := idx
+		for end < len(d) && !isSvgPathCommand(d[end]) {
+			end++
+		}
+
+		args, err := parseSvgNumbers(d[idx:end])
+		if err != nil {
+			return err
+		}
+
+		idx = end
+
+		if argc == 0 {
+			x, y = startX, startY
+
+			continue
+		} else if len(args) == 0 || len(args)%argc != 0 {
+			return fmt.Errorf("command (%c) expects arguments in multiples of %d", c, argc)
+		}
+
+		for argIdx := 0; argIdx < len(args); argIdx += argc {
+			set := args[argIdx : argIdx+argc]
+
+			switch upper {
+			case 'H':
+				if relative {
+					x += set[0]
+				} else {
+					x = set[0]
+				}
+
+				b.add(x, y)
+
+				continue
+			case 'V':
+				if relative {
+					y += set[0]
+				} else {
+					y = set[0]
+				}
+
+				b.add(x, y)
+
+				continue
+			}
+
+			// every pair is either a control point or the end point, which is last
+			for pairIdx := 0; pairIdx < len(set); pairIdx += 2 {
+				px, py := set[pairIdx], set[pairIdx+1]
+				if relative {
+					px, py = px+x, py+y
+				}
+
+				b.add(px, py)
+
+				if pairIdx == len(set)-2 {
+					x, y = px, py
+				}
+			}
+
+			if upper == 'M' && argIdx == 0 {
+				startX, startY = x, y
+			}
+		}
+	}
+
+	return nil
+}
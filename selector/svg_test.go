@@ -0,0 +1,62 @@
+package selector
+
+import "testing"
+
+func TestSvgBounds(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		svg      string
+		expected [4]float64
+	}{
+		{
+			name:     "polygon",
+			svg:      `<svg xmlns="http://www.w3.org/2000/svg"><polygon points="270,1900 1530,1900 1530,1610 1315,1300 1200,986 904,661 600,986 270,1300"/></svg>`,
+			expected: [4]float64{270, 661, 1260, 1239},
+		},
+		{
+			name:     "rect and circle",
+			svg:      `<svg><rect x="10" y="20" width="30" height="40"/><circle cx="100" cy="100" r="5"/></svg>`,
+			expected: [4]float64{10, 20, 95, 85},
+		},
+		{
+			name:     "relative path",
+			svg:      `<svg><path d="M10,10 l20,0 v30 h-40z"/></svg>`,
+			expected: [4]float64{-10, 10, 40, 30},
+		},
+		{
+			name:     "curve control points",
+			svg:      `<svg><g><path d="M0 0C10-10 20 10 30 0"/></g></svg>`,
+			expected: [4]float64{0, -10, 30, 20},
+		},
+	} {
+		bounds, err := SvgBounds(tc.svg)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.name, err)
+		} else if _e, _a := tc.expected, bounds; _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.name, _e, _a)
+		}
+	}
+
+	for _, svg := range []string{
+		`<svg></svg>`,
+		`<svg><path d="M0 0 A10 10 0 0 1 20 20"/></svg>`,
+		`<svg><polygon points="0,0 10" /></svg>`,
+		`<svg><rect x="0" y="0" width="10" height="10" transform="rotate(45)"/></svg>`,
+		`<svg><rect`,
+	} {
+		if _, err := SvgBounds(svg); err == nil {
+			t.Fatalf("%s: expected error", svg)
+		}
+	}
+}
+
+func TestSvgBounds_MediaFragment(t *testing.T) {
+	bounds, err := SvgBounds(`<svg><polygon points="10.5,20.2 30.1,20.2 30.1,40.9"/></svg>`)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	if _e, _a := "xywh=10,20,21,21", NewPixelMediaFragment(bounds).String(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,261 @@
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/render"
+)
+
+// InfoPath is the path of the image information document, relative to the image.
+const InfoPath = "info.json"
+
+// ExportOptions contains the properties which affect how an image is exported.
+type ExportOptions struct {
+	// ImageInformation must be configured with, at a minimum, the ID, profile, width, height, and the sizes and tiles
+	// which should be rendered.
+	ImageInformation iiifimageapi.ImageInformation
+
+	// Format is the format of rendered images. If empty, "jpg" will be used.
+	Format string
+
+	// DefaultQuality is the quality rendered for "default". If empty, it is chosen from the color model of the source
+	// image, falling back to "gray" when the suggested quality (e.g. "bitonal") is not supported by ImageInformation.
+	DefaultQuality string
+
+	// Workers is the number of images rendered in parallel. If less than 1, a single worker will be used.
+	Workers int
+
+	// SkipUpToDate may be set to skip images which already exist and were modified after SourceModTime.
+	SkipUpToDate  bool
+	SourceModTime time.Time
+
+	// Renderer is used to render and encode each image.
+	Renderer render.Renderer
+}
+
+// exportDefaultQuality suggests the quality of src, as long as info supports it. The "color" and "gray" qualities are
+// always supported, and a bitonal image is also gray.
+func exportDefaultQuality(info iiifimageapi.ImageInformation, src image.Image) string {
+	quality := iiifimageapi.DefaultQualityForImage(src)
+
+	switch quality {
+	case "color", "gray":
+		return quality
+	}
+
+	for _, extra := range info.ExtraQualities {
+		if extra == quality {
+			return quality
+		}
+	}
+
+	if spec, ok := iiifimageapi.DefaultComplianceLevels.GetByName(info.Profile); ok {
+		for _, base := range spec.BaseQualities() {
+			if base == quality {
+				return quality
+			}
+		}
+	}
+
+	return "gray"
+}
+
+func (o ExportOptions) getFormat() string {
+	if o.Format != "" {
+		return o.Format
+	}
+
+	return "jpg"
+}
+
+func (o ExportOptions) getWorkers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+
+	return 1
+}
+
+// ExportResult summarizes an export.
+type ExportResult struct {
+	Rendered int
+	Skipped  int
+
+	Manifest Manifest
+}
+
+// Export renders every planned image (see [Plan]) of src into out, followed by the info.json document and a manifest
+// of every file. Paths are relative to the image (e.g. `full/max/0/default.jpg`). SkipUpToDate is only supported
+// when out implements [fs.StatFS] (e.g. [DirOutput]).
+func Export(ctx context.Context, src image.Image, out Output, opts ExportOptions) (ExportResult, error) {
+	defaultQuality := opts.DefaultQuality
+	if defaultQuality == "" {
+		defaultQuality = exportDefaultQuality(opts.ImageInformation, src)
+	}
+
+	var existing fs.StatFS
+
+	if opts.SkipUpToDate {
+		var ok bool
+
+		existing, ok = out.(fs.StatFS)
+		if !ok {
+			return ExportResult{}, errors.New("skipping up-to-date files requires an output which implements fs.StatFS")
+		}
+	}
+
+	planned, err := Plan(opts.ImageInformation, opts.getFormat(), defaultQuality)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	// converting once avoids converting the full image for every tile
+	src = render.ToRGBA(src)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var result ExportResult
+	var resultMu sync.Mutex
+	var firstErr error
+
+	manifest := &manifestBuilder{}
+	jobs := make(chan PlannedFile)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < opts.getWorkers(); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for file := range jobs {
+				skipped, err := exportFile(src, out, existing, manifest, file, opts)
+
+				resultMu.Lock()
+
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("exporting %s: %v", file.Path, err)
+					}
+
+					cancel()
+				} else if skipped {
+					result.Skipped++
+				} else {
+					result.Rendered++
+				}
+
+				resultMu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, file := range planned {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- file:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, firstErr
+	} else if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	err = writeJSONFile(out, manifest, InfoPath, opts.ImageInformation)
+	if err != nil {
+		return result, err
+	}
+
+	result.Manifest = manifest.Manifest()
+
+	err = writeJSONFile(out, nil, ManifestPath, result.Manifest)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func exportFile(src image.Image, out Output, existing fs.StatFS, manifest *manifestBuilder, file PlannedFile, opts ExportOptions) (bool, error) {
+	if existing != nil {
+		stat, err := existing.Stat(file.Path)
+		if err == nil && !stat.ModTime().Before(opts.SourceModTime) {
+			return true, addExistingManifestFile(existing, manifest, file.Path)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+	}
+
+	return false, out.WriteFile(file.Path, func(w io.Writer) error {
+		mw := newManifestWriter(w)
+
+		err := opts.Renderer.RenderTo(mw, src, file.Params)
+		if err != nil {
+			return err
+		}
+
+		manifest.add(mw.ManifestFile(file.Path))
+
+		return nil
+	})
+}
+
+func addExistingManifestFile(existing fs.FS, manifest *manifestBuilder, path string) error {
+	f, err := existing.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	mw := newManifestWriter(io.Discard)
+
+	_, err = io.Copy(mw, f)
+	if err != nil {
+		return err
+	}
+
+	manifest.add(mw.ManifestFile(path))
+
+	return nil
+}
+
+func writeJSONFile(out Output, manifest *manifestBuilder, path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return out.WriteFile(path, func(w io.Writer) error {
+		mw := newManifestWriter(w)
+
+		_, err := mw.Write(data)
+		if err != nil {
+			return err
+		}
+
+		if manifest != nil {
+			manifest.add(mw.ManifestFile(path))
+		}
+
+		return nil
+	})
+}
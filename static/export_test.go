@@ -0,0 +1,183 @@
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+func exampleSourceImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 100; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x * 2), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+
+	return img
+}
+
+func exampleImageInformation() iiifimageapi.ImageInformation {
+	return iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      "https://example.com/iiif/example",
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   100,
+		Height:  60,
+		Sizes: []iiifimageapi.ImageInformationSize{
+			{Width: 50, Height: 30},
+		},
+		Tiles: []iiifimageapi.ImageInformationTile{
+			{Width: 32, ScaleFactors: []uint32{1, 2, 4}},
+		},
+	})
+}
+
+func TestPlan(t *testing.T) {
+	planned, err := Plan(exampleImageInformation(), "jpg", "color")
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	// 4x2 at 1, 2x1 at 2, 1x1 at 4, and 1 size
+	if _e, _a := 12, len(planned); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "0,0,32,32/32,32/0/default.jpg", planned[0].Path; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "full/25,15/0/default.jpg", planned[len(planned)-2].Path; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "full/50,30/0/default.jpg", planned[len(planned)-1].Path; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	opts := ExportOptions{
+		ImageInformation: exampleImageInformation(),
+		Workers:          3,
+		SkipUpToDate:     true,
+		SourceModTime:    time.Now().Add(-time.Hour),
+	}
+
+	result, err := Export(context.Background(), exampleSourceImage(), NewDirOutput(dir), opts)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 12, result.Rendered; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 13, len(result.Manifest.Files); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "96,32,4,28", "4,28", "0", "default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	defer f.Close()
+
+	cfg, err := jpeg.DecodeConfig(f)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := [2]int{4, 28}, [2]int{cfg.Width, cfg.Height}; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	infoBytes, err := os.ReadFile(filepath.Join(dir, InfoPath))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	var info iiifimageapi.ImageInformation
+
+	if err := json.Unmarshal(infoBytes, &info); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "https://example.com/iiif/example", info.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	firstManifest := result.Manifest
+
+	result, err = Export(context.Background(), exampleSourceImage(), NewDirOutput(dir), opts)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 12, result.Skipped; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := firstManifest, result.Manifest; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestExport_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Export(ctx, exampleSourceImage(), NewMemoryOutput(), ExportOptions{
+		ImageInformation: exampleImageInformation(),
+	})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
+
+func TestExport_SkipUpToDateUnsupported(t *testing.T) {
+	_, err := Export(context.Background(), exampleSourceImage(), NewMemoryOutput(), ExportOptions{
+		ImageInformation: exampleImageInformation(),
+		SkipUpToDate:     true,
+	})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
+
+func exampleBitonalSourceImage() *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, 100, 60), color.Palette{color.Black, color.White})
+
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 100; x++ {
+			img.SetColorIndex(x, y, uint8((x/10+y/10)%2))
+		}
+	}
+
+	return img
+}
+
+func TestExport_Bitonal(t *testing.T) {
+	src := exampleBitonalSourceImage()
+
+	result, err := Export(context.Background(), src, NewMemoryOutput(), ExportOptions{
+		ImageInformation: exampleImageInformation(),
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 12, result.Rendered; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _e, _a := "gray", exportDefaultQuality(exampleImageInformation(), src); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	info := exampleImageInformation()
+	info.ExtraQualities = []string{"bitonal"}
+
+	if _e, _a := "bitonal", exportDefaultQuality(info, src); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	_, err = Export(context.Background(), src, NewMemoryOutput(), ExportOptions{
+		ImageInformation: info,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+}
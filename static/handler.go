@@ -0,0 +1,488 @@
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/httpcache"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+const (
+	// InfoMediaType is the default media type of info.json responses.
+	InfoMediaType = "application/json"
+
+	// InfoMediaTypeJSONLD is the media type of info.json responses when requested by the Accept header.
+	InfoMediaTypeJSONLD = `application/ld+json;profile="` + iiifimageapi.Context + `"`
+)
+
+// HandlerOptions contains the properties which affect how a [Handler] serves requests.
+type HandlerOptions struct {
+	// DefaultQuality is the quality which "default" images represent. If empty, "color" is used.
+	DefaultQuality string
+
+	// Formats is used for the Content-Type of images. If nil, [iiifimageapi.DefaultFormats] is used. Content of unknown
+	// formats is sniffed.
+	Formats *iiifimageapi.FormatRegistry
+
+	// LinkHeaders may be set to true to send the canonical and profile Link headers on image responses and advertise
+	// them in info.json. Otherwise, they are only sent when the stored info.json already advertises them.
+	LinkHeaders bool
+
+	// CachePolicy is the Cache-Control of info.json and image responses. Both also have a strong ETag and Last-Modified
+	// for conditional requests.
+	CachePolicy httpcache.Policy
+}
+
+// Handler serves a collection of static trees from a file system. Each image is a `{identifier}` directory (escaped as
+// a single path segment, as written by the iiif-static command) containing info.json.
+//
+// Requests are resolved against the stored info.json. Equivalent, non-canonical requests for an existing file (e.g. a
+// `pct:` region or `w,` size of a tile) are redirected to the canonical URL, and other requests result in the error
+// status described by the specification.
+type Handler struct {
+	fsys fs.FS
+	opts HandlerOptions
+}
+
+var _ http.Handler = &Handler{}
+
+// NewHandler creates a handler which serves fsys (e.g. an embed.FS or os.DirFS).
+func NewHandler(fsys fs.FS, opts HandlerOptions) *Handler {
+	if opts.DefaultQuality == "" {
+		opts.DefaultQuality = "color"
+	}
+
+	if opts.Formats == nil {
+		opts.Formats = iiifimageapi.DefaultFormats
+	}
+
+	return &Handler{
+		fsys: fsys,
+		opts: opts,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+
+	identifier, err := url.PathUnescape(segments[0])
+	if err != nil || identifier == "" {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	dir := url.PathEscape(identifier)
+
+	if dir == "." || dir == ".." || !fs.ValidPath(dir) {
+		// not a directory of fsys (e.g. `..` would refer to its parent)
+		http.NotFound(w, r)
+
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		h.serveBaseRedirect(w, dir, segments[0]+"/"+InfoPath)
+	case len(segments) == 2 && segments[1] == "":
+		h.serveBaseRedirect(w, dir, InfoPath)
+	case len(segments) == 2 && segments[1] == InfoPath:
+		h.serveInfo(w, r, dir)
+	case len(segments) == 5:
+		h.serveImage(w, r, dir, strings.Join(segments[1:], "/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) serveBaseRedirect(w http.ResponseWriter, dir, location string) {
+	if _, err := fs.Stat(h.fsys, path.Join(dir, InfoPath)); err != nil {
+		h.serveFSError(w, err)
+
+		return
+	}
+
+	serveRedirect(w, location, http.StatusSeeOther)
+}
+
+func (h *Handler) serveInfo(w http.ResponseWriter, r *http.Request, dir string) {
+	mediaType := InfoMediaType
+
+	if strings.Contains(r.Header.Get("Accept"), "application/ld+json") {
+		mediaType = InfoMediaTypeJSONLD
+	}
+
+	stat, err := fs.Stat(h.fsys, path.Join(dir, InfoPath))
+	if err != nil {
+		h.serveFSError(w, err)
+
+		return
+	} else if stat.IsDir() {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	infoBytes, err := fs.ReadFile(h.fsys, path.Join(dir, InfoPath))
+	if err != nil {
+		h.serveFSError(w, err)
+
+		return
+	}
+
+	if h.opts.LinkHeaders {
+		infoBytes, err = appendInfoFeatures(infoBytes, linkHeaderFeatures...)
+		if err != nil {
+			h.serveFSError(w, err)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Cache-Control", h.opts.CachePolicy.InfoCacheControl())
+	w.Header().Set("ETag", httpcache.InfoETag(infoBytes, mediaType))
+
+	http.ServeContent(w, r, InfoPath, stat.ModTime(), bytes.NewReader(infoBytes))
+}
+
+func (h *Handler) serveImage(w http.ResponseWriter, r *http.Request, dir, rawPath string) {
+	info, err := h.readImageInformation(dir)
+	if err != nil {
+		h.serveFSError(w, err)
+
+		return
+	}
+
+	raw, err := imagerequest.RawParamsFromString(rawPath)
+	if err != nil {
+		h.serveError(w, err)
+
+		return
+	}
+
+	parsed, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		h.serveError(w, err)
+
+		return
+	}
+
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   h.opts.DefaultQuality,
+	}
+
+	// first, find whether an equivalent file exists regardless of the features it took to describe it
+	resolveOptions.IgnoreFeatureErrors = true
+
+	resolved, err := parsed.Resolve(resolveOptions)
+	if err != nil {
+		h.serveError(w, err)
+
+		return
+	}
+
+	canonicalPath := resolved.Canonical().String()
+
+	if _, err := fs.Stat(h.fsys, path.Join(dir, canonicalPath)); err == nil {
+		if canonicalRaw, err := imagerequest.RawParamsFromString(canonicalPath); err == nil && canonicalRaw != raw {
+			serveRedirect(w, "../../../"+canonicalPath, http.StatusFound)
+
+			return
+		}
+
+		if format, ok := h.opts.Formats.GetByName(resolved.Format()); ok {
+			w.Header().Set("Content-Type", format.MediaType)
+		}
+
+		h.setLinkHeaders(w, info, resolved)
+		h.serveImageFile(w, r, path.Join(dir, canonicalPath), resolved)
+
+		return
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		h.serveFSError(w, err)
+
+		return
+	}
+
+	value := pixelset.Value{
+		Region: resolved.RegionPixels(),
+		Size:   resolved.SizePixels(),
+	}
+
+	if pixelset.NewImageDomain(info).Contains(value) {
+		// advertised, but the tree is incomplete
+		http.Error(w, "image not found", http.StatusNotFound)
+
+		return
+	}
+
+	// otherwise, prefer the error a dynamic server would have returned
+	resolveOptions.IgnoreFeatureErrors = false
+
+	_, err = parsed.Resolve(resolveOptions)
+	if err != nil {
+		h.serveError(w, err)
+
+		return
+	}
+
+	// valid, but not pre-rendered; the specification considers it unsupported for this image
+	http.Error(w, "image not found", http.StatusNotFound)
+}
+
+// setImageValidators sets the caching headers of an image. The source version is the hash of the served content, so
+// the tag changes whenever the file does, even on file systems without modification times (e.g. embed.FS).
+func (h *Handler) setImageValidators(w http.ResponseWriter, resolved imagerequest.ResolvedParams, content io.ReadSeeker) error {
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, content); err != nil {
+		return err
+	} else if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	w.Header().Set("Cache-Control", h.opts.CachePolicy.ImageCacheControl())
+	w.Header().Set("ETag", httpcache.ImageETag(resolved, hex.EncodeToString(hash.Sum(nil))))
+
+	return nil
+}
+
+var linkHeaderFeatures = iiifimageapi.FeatureNameList{
+	iiifimageapi.FeatureNameCanonicalLinkHeader,
+	iiifimageapi.FeatureNameProfileLinkHeader,
+}
+
+func (h *Handler) setLinkHeaders(w http.ResponseWriter, info iiifimageapi.ImageInformation, resolved imagerequest.ResolvedParams) {
+	spec, ok := iiifimageapi.DefaultComplianceLevels.GetByName(info.Profile)
+	if !ok {
+		return
+	}
+
+	features := info.ExtraFeatures
+
+	if h.opts.LinkHeaders {
+		features = appendMissingFeatures(features, linkHeaderFeatures...)
+	}
+
+	for _, value := range imagerequest.LinkHeaders(info.ID, resolved, spec, features) {
+		w.Header().Add("Link", value)
+	}
+}
+
+func appendMissingFeatures(list iiifimageapi.FeatureNameList, features ...iiifimageapi.FeatureName) iiifimageapi.FeatureNameList {
+	list = append(iiifimageapi.FeatureNameList(nil), list...)
+
+	for _, feature := range features {
+		var found bool
+
+		for _, existing := range list {
+			if existing == feature {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			list = append(list, feature)
+		}
+	}
+
+	list.Sort()
+
+	return list
+}
+
+// appendInfoFeatures adds features to the extraFeatures of an encoded info.json document. Only that property is
+// rewritten (or added after the last property), so the rest of the document, including any properties which are not
+// modeled by [iiifimageapi.ImageInformation], is served as stored.
+func appendInfoFeatures(data []byte, features ...iiifimageapi.FeatureName) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("decoding %s: %v", InfoPath, err)
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("decoding %s: expected object", InfoPath)
+	}
+
+	var existing iiifimageapi.FeatureNameList
+
+	existingStart, existingEnd := int64(-1), int64(-1)
+	lastEnd := dec.InputOffset()
+
+	// the whitespace before the first property, used to format an added property
+	var indent []byte
+
+	for dec.More() {
+		keyStart := lastEnd
+
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %v", InfoPath, err)
+		}
+
+		var raw json.RawMessage
+
+		err = dec.Decode(&raw)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %v", InfoPath, err)
+		}
+
+		lastEnd = dec.InputOffset()
+
+		if indent == nil {
+			indent = bytes.TrimLeft(data[keyStart:keyStart+int64(bytes.IndexByte(data[keyStart:], '"'))], ",")
+		}
+
+		if tok == "extraFeatures" {
+			err = json.Unmarshal(raw, &existing)
+			if err != nil {
+				return nil, fmt.Errorf("decoding %s: extraFeatures: %v", InfoPath, err)
+			}
+
+			existingStart, existingEnd = lastEnd-int64(len(raw)), lastEnd
+		}
+	}
+
+	merged := appendMissingFeatures(existing, features...)
+	if len(merged) == len(existing) {
+		return data, nil
+	}
+
+	mergedBytes, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+
+	if existingStart >= 0 {
+		patched = append(patched, data[:existingStart]...)
+		patched = append(patched, mergedBytes...)
+		patched = append(patched, data[existingEnd:]...)
+
+		return patched, nil
+	}
+
+	patched = append(patched, data[:lastEnd]...)
+
+	if indent != nil {
+		patched = append(patched, ',')
+	}
+
+	patched = append(patched, indent...)
+	patched = append(patched, `"extraFeatures":`...)
+
+	if len(indent) > 0 {
+		patched = append(patched, ' ')
+	}
+
+	patched = append(patched, mergedBytes...)
+	patched = append(patched, data[lastEnd:]...)
+
+	return patched, nil
+}
+
+func (h *Handler) readImageInformation(dir string) (iiifimageapi.ImageInformation, error) {
+	infoBytes, err := fs.ReadFile(h.fsys, path.Join(dir, InfoPath))
+	if err != nil {
+		return iiifimageapi.ImageInformation{}, err
+	}
+
+	var info iiifimageapi.ImageInformation
+
+	err = json.Unmarshal(infoBytes, &info)
+	if err != nil {
+		return iiifimageapi.ImageInformation{}, fmt.Errorf("decoding %s: %v", InfoPath, err)
+	}
+
+	return info, nil
+}
+
+func (h *Handler) serveImageFile(w http.ResponseWriter, r *http.Request, name string, resolved imagerequest.ResolvedParams) {
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		h.serveFSError(w, err)
+
+		return
+	}
+
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		h.serveFSError(w, err)
+
+		return
+	} else if stat.IsDir() {
+		http.Error(w, "not found", http.StatusNotFound)
+
+		return
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			h.serveFSError(w, err)
+
+			return
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	if err := h.setImageValidators(w, resolved, content); err != nil {
+		h.serveFSError(w, err)
+
+		return
+	}
+
+	http.ServeContent(w, r, path.Base(name), stat.ModTime(), content)
+}
+
+func (h *Handler) serveFSError(w http.ResponseWriter, err error) {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		http.Error(w, "image not found", http.StatusNotFound)
+
+		return
+	}
+
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (h *Handler) serveError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), iiifimageapi.HTTPStatusCode(err))
+}
+
+// serveRedirect uses a relative location since, unlike [http.Redirect], it must remain correct for handlers mounted
+// with [http.StripPrefix].
+func serveRedirect(w http.ResponseWriter, location string, code int) {
+	w.Header().Set("Location", location)
+	w.WriteHeader(code)
+}
@@ -0,0 +1,337 @@
+package static
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/httpcache"
+)
+
+func exampleHandler(t *testing.T) http.Handler {
+	fsys := fstest.MapFS{}
+
+	for path, file := range exampleExportFS(t) {
+		fsys["example/"+path] = file
+	}
+
+	return http.StripPrefix("/iiif", NewHandler(fsys, HandlerOptions{}))
+}
+
+func TestHandler(t *testing.T) {
+	handler := exampleHandler(t)
+
+	for _, tc := range []struct {
+		method      string
+		path        string
+		accept      string
+		status      int
+		location    string
+		contentType string
+	}{
+		{path: "/iiif/example", status: http.StatusSeeOther, location: "example/info.json"},
+		{path: "/iiif/example/", status: http.StatusSeeOther, location: "info.json"},
+		{path: "/iiif/example/info.json", status: http.StatusOK, contentType: InfoMediaType},
+		{path: "/iiif/example/info.json", accept: "application/ld+json", status: http.StatusOK, contentType: InfoMediaTypeJSONLD},
+		{path: "/iiif/example/0,0,32,32/32,32/0/default.jpg", status: http.StatusOK, contentType: "image/jpeg"},
+		{path: "/iiif/example/0,0,32,32/32,/0/default.jpg", status: http.StatusFound, location: "../../../0,0,32,32/32,32/0/default.jpg"},
+		{path: "/iiif/example/pct:0,0,100,100/25,/0/default.jpg", status: http.StatusFound, location: "../../../full/25,15/0/default.jpg"},
+		{path: "/iiif/example/full/pct:50/0/color.jpg", status: http.StatusFound, location: "../../../full/50,30/0/default.jpg"},
+		{path: "/iiif/example/full/max/0/default.jpg", status: http.StatusNotFound},
+		{path: "/iiif/example/0,0,10,10/10,10/0/default.jpg", status: http.StatusNotImplemented},
+		{path: "/iiif/example/invalid/max/0/default.jpg", status: http.StatusBadRequest},
+		{path: "/iiif/example/full/max/0/default.bmp", status: http.StatusBadRequest},
+		{path: "/iiif/unknown/info.json", status: http.StatusNotFound},
+		{path: "/iiif/../info.json", status: http.StatusNotFound},
+		{path: "/iiif/%2E%2E/info.json", status: http.StatusNotFound},
+		{path: "/iiif/%2E/info.json", status: http.StatusNotFound},
+		{path: "/iiif/%2E%2E/full/max/0/default.jpg", status: http.StatusNotFound},
+		{path: "/iiif/%2E%2E", status: http.StatusNotFound},
+		{path: "/iiif/unknown/full/max/0/default.jpg", status: http.StatusNotFound},
+		{path: "/iiif/example/full/max/0", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/iiif/example/info.json", status: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tc.method+tc.path, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			r := httptest.NewRequest(method, tc.path, nil)
+
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if _e, _a := tc.status, w.Code; _e != _a {
+				t.Fatalf("expected `%v` but got: %v (%s)", _e, _a, w.Body.String())
+			} else if _e, _a := tc.location, w.Header().Get("Location"); _e != _a {
+				t.Fatalf("expected `%v` but got: %v", _e, _a)
+			} else if _e, _a := "*", w.Header().Get("Access-Control-Allow-Origin"); _e != _a {
+				t.Fatalf("expected `%v` but got: %v", _e, _a)
+			}
+
+			if tc.contentType != "" {
+				if _e, _a := tc.contentType, w.Header().Get("Content-Type"); _e != _a {
+					t.Fatalf("expected `%v` but got: %v", _e, _a)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_IncompleteTree(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	for path, file := range exampleExportFS(t) {
+		if path == "full/50,30/0/default.jpg" {
+			continue
+		}
+
+		fsys["example/"+path] = file
+	}
+
+	w := httptest.NewRecorder()
+	NewHandler(fsys, HandlerOptions{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/example/full/50,/0/default.jpg", nil))
+
+	if _e, _a := http.StatusNotFound, w.Code; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestHandler_LinkHeaders(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	for path, file := range exampleExportFS(t) {
+		fsys["example/"+path] = file
+	}
+
+	handler := http.StripPrefix("/iiif", NewHandler(fsys, HandlerOptions{}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/iiif/example/full/50,30/0/default.jpg", nil))
+
+	if _e, _a := 0, len(w.Header().Values("Link")); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	handler = http.StripPrefix("/iiif", NewHandler(fsys, HandlerOptions{LinkHeaders: true}))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/iiif/example/full/50,30/0/default.jpg", nil))
+
+	if _e, _a := http.StatusOK, w.Code; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{
+		`<https://example.com/iiif/example/full/50,30/0/default.jpg>;rel="canonical"`,
+		`<http://iiif.io/api/image/3/level0.json>;rel="profile"`,
+	}, w.Header().Values("Link"); !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/iiif/example/info.json", nil))
+
+	var info iiifimageapi.ImageInformation
+
+	err := json.Unmarshal(w.Body.Bytes(), &info)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := (iiifimageapi.FeatureNameList{iiifimageapi.FeatureNameCanonicalLinkHeader, iiifimageapi.FeatureNameProfileLinkHeader}), info.ExtraFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestHandler_Caching(t *testing.T) {
+	handler := exampleHandler(t)
+
+	for _, path := range []string{"/iiif/example/info.json", "/iiif/example/full/50,30/0/default.jpg"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+			etag := w.Header().Get("ETag")
+
+			if _e, _a := http.StatusOK, w.Code; _e != _a {
+				t.Fatalf("expected `%v` but got: %v", _e, _a)
+			} else if etag == "" || strings.HasPrefix(etag, "W/") {
+				t.Fatalf("expected strong etag but got: %v", etag)
+			} else if w.Header().Get("Cache-Control") == "" {
+				t.Fatal("expected Cache-Control")
+			}
+
+			for _, method := range []string{http.MethodGet, http.MethodHead} {
+				r := httptest.NewRequest(method, path, nil)
+				r.Header.Set("If-None-Match", etag)
+
+				w = httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if _e, _a := http.StatusNotModified, w.Code; _e != _a {
+					t.Fatalf("%s: expected `%v` but got: %v", method, _e, _a)
+				} else if _e, _a := etag, w.Header().Get("ETag"); _e != _a {
+					t.Fatalf("%s: expected `%v` but got: %v", method, _e, _a)
+				}
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/iiif/example/info.json", nil))
+
+	if _e, _a := httpcache.DefaultInfoCacheControl, w.Header().Get("Cache-Control"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "Accept", w.Header().Get("Vary"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// the negotiated media type is a different representation
+	r := httptest.NewRequest(http.MethodGet, "/iiif/example/info.json", nil)
+	r.Header.Set("Accept", "application/ld+json")
+
+	jsonldW := httptest.NewRecorder()
+	handler.ServeHTTP(jsonldW, r)
+
+	if _e, _a := InfoMediaTypeJSONLD, jsonldW.Header().Get("Content-Type"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _a := jsonldW.Header().Get("ETag"); _a == w.Header().Get("ETag") {
+		t.Fatalf("expected different tag for media type but got: %v", _a)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/iiif/example/full/50,30/0/default.jpg", nil))
+
+	if _e, _a := httpcache.DefaultImageCacheControl, w.Header().Get("Cache-Control"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// redirects are not cached as images
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/iiif/example/full/50,/0/default.jpg", nil))
+
+	if _e, _a := "", w.Header().Get("ETag"); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestHandler_ImageETag(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	for path, file := range exampleExportFS(t) {
+		fsys["example/"+path] = file
+	}
+
+	getETag := func() string {
+		w := httptest.NewRecorder()
+		NewHandler(fsys, HandlerOptions{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/example/full/50,30/0/default.jpg", nil))
+
+		if _e, _a := http.StatusOK, w.Code; _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+
+		return w.Header().Get("ETag")
+	}
+
+	etag := getETag()
+
+	if _e, _a := etag, getETag(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// a re-export may change images without changing info.json (or any modification time)
+	fsys["example/full/50,30/0/default.jpg"] = &fstest.MapFile{Data: append([]byte(nil), fsys["example/0,0,32,32/32,32/0/default.jpg"].Data...)}
+
+	if _a := getETag(); etag == _a {
+		t.Fatalf("expected different tag for changed content but got: %v", _a)
+	}
+}
+
+func TestHandler_InvalidIdentifier(t *testing.T) {
+	dir := t.TempDir()
+
+	for path, file := range exampleExportFS(t) {
+		fullPath := filepath.Join(dir, "example", filepath.FromSlash(path))
+
+		err := os.MkdirAll(filepath.Dir(fullPath), 0o755)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+
+		err = os.WriteFile(fullPath, file.Data, 0o644)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+	}
+
+	// a tree at the root of the file system must not be served as an identifier of `.`
+	err := os.WriteFile(filepath.Join(dir, InfoPath), []byte("{}"), 0o644)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	handler := NewHandler(os.DirFS(dir), HandlerOptions{})
+
+	for _, path := range []string{
+		"/../info.json",
+		"/%2E%2E/info.json",
+		"/%2E%2E/full/max/0/default.jpg",
+		"/%2E/info.json",
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if _e, _a := http.StatusNotFound, w.Code; _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", path, _e, _a)
+		}
+	}
+}
+
+func TestAppendInfoFeatures(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "indented",
+			data:     "{\n  \"id\": \"https://example.com/iiif/example\",\n  \"label\": {\"en\": [\"Example\"]},\n  \"x-custom\": [1, 2]\n}\n",
+			expected: "{\n  \"id\": \"https://example.com/iiif/example\",\n  \"label\": {\"en\": [\"Example\"]},\n  \"x-custom\": [1, 2],\n  \"extraFeatures\": [\"canonicalLinkHeader\",\"profileLinkHeader\"]\n}\n",
+		},
+		{
+			name:     "existing",
+			data:     `{"extraFeatures":["mirroring"],"x-custom":true}`,
+			expected: `{"extraFeatures":["canonicalLinkHeader","mirroring","profileLinkHeader"],"x-custom":true}`,
+		},
+		{
+			name:     "unchanged",
+			data:     `{"extraFeatures": ["profileLinkHeader", "canonicalLinkHeader"]}`,
+			expected: `{"extraFeatures": ["profileLinkHeader", "canonicalLinkHeader"]}`,
+		},
+		{
+			name:     "empty",
+			data:     `{}`,
+			expected: `{"extraFeatures":["canonicalLinkHeader","profileLinkHeader"]}`,
+		},
+	} {
+		actual, err := appendInfoFeatures([]byte(tc.data), linkHeaderFeatures...)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.name, err)
+		} else if _e, _a := tc.expected, string(actual); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.name, _e, _a)
+		}
+	}
+
+	if _, err := appendInfoFeatures([]byte(`[]`), linkHeaderFeatures...); err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
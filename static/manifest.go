@@ -0,0 +1,82 @@
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ManifestPath is the path of the export manifest, relative to the image.
+const ManifestPath = "manifest.json"
+
+// Manifest lists every file of an export, other than the manifest itself.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes the content of a single exported file.
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type manifestBuilder struct {
+	mu    sync.Mutex
+	files []ManifestFile
+}
+
+func (b *manifestBuilder) add(file ManifestFile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.files = append(b.files, file)
+}
+
+func (b *manifestBuilder) Manifest() Manifest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	files := append([]ManifestFile(nil), b.files...)
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return Manifest{
+		Files: files,
+	}
+}
+
+type manifestWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	size int64
+}
+
+func newManifestWriter(w io.Writer) *manifestWriter {
+	return &manifestWriter{
+		w:    w,
+		hash: sha256.New(),
+	}
+}
+
+func (w *manifestWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+
+	return n, err
+}
+
+func (w *manifestWriter) ManifestFile(path string) ManifestFile {
+	return ManifestFile{
+		Path:   path,
+		Size:   w.size,
+		SHA256: hex.EncodeToString(w.hash.Sum(nil)),
+	}
+}
@@ -0,0 +1,553 @@
+package static
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/internal/uint32ptr"
+)
+
+// ImageInformationV2Context is the JSON-LD context of Image API 2.x info.json documents.
+const ImageInformationV2Context = "http://iiif.io/api/image/2/context.json"
+
+// ImageInformationV2 is the subset of an Image API 2.x info.json document which has an equivalent in 3.0.
+type ImageInformationV2 struct {
+	Context  string `json:"@context"`
+	ID       string `json:"@id"`
+	Protocol string `json:"protocol"`
+
+	Width  uint32 `json:"width"`
+	Height uint32 `json:"height"`
+
+	Sizes []iiifimageapi.ImageInformationSize `json:"sizes,omitempty"`
+	Tiles []iiifimageapi.ImageInformationTile `json:"tiles,omitempty"`
+
+	// Profile is the compliance level document followed by any number of descriptions of additional capabilities.
+	Profile ImageInformationV2Profile `json:"profile"`
+
+	License interface{} `json:"license,omitempty"`
+}
+
+// ImageInformationV2Profile is the `profile` property of an Image API 2.x document.
+type ImageInformationV2Profile struct {
+	ComplianceLevel string
+	Extensions      []ImageInformationV2ProfileExtension
+}
+
+// ImageInformationV2ProfileExtension describes capabilities beyond the compliance level of a 2.x document.
+type ImageInformationV2ProfileExtension struct {
+	Formats   []string `json:"formats,omitempty"`
+	Qualities []string `json:"qualities,omitempty"`
+	Supports  []string `json:"supports,omitempty"`
+
+	MaxWidth  *uint32 `json:"maxWidth,omitempty"`
+	MaxHeight *uint32 `json:"maxHeight,omitempty"`
+	MaxArea   *uint32 `json:"maxArea,omitempty"`
+}
+
+func (p *ImageInformationV2Profile) UnmarshalJSON(data []byte) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*p = ImageInformationV2Profile{
+			ComplianceLevel: single,
+		}
+
+		return nil
+	}
+
+	var items []json.RawMessage
+
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return fmt.Errorf("profile: expecting string or array: %v", err)
+	}
+
+	*p = ImageInformationV2Profile{}
+
+	for itemIdx, item := range items {
+		if err := json.Unmarshal(item, &single); err == nil {
+			if p.ComplianceLevel == "" {
+				p.ComplianceLevel = single
+			}
+
+			continue
+		}
+
+		var extension ImageInformationV2ProfileExtension
+
+		err := json.Unmarshal(item, &extension)
+		if err != nil {
+			return fmt.Errorf("profile[%d]: %v", itemIdx, err)
+		}
+
+		p.Extensions = append(p.Extensions, extension)
+	}
+
+	return nil
+}
+
+func (p ImageInformationV2Profile) MarshalJSON() ([]byte, error) {
+	var items []interface{}
+
+	items = append(items, p.ComplianceLevel)
+
+	for _, extension := range p.Extensions {
+		items = append(items, extension)
+	}
+
+	return json.Marshal(items)
+}
+
+var v2ComplianceLevels = map[string]iiifimageapi.ComplianceLevelName{
+	"http://iiif.io/api/image/2/level0.json": iiifimageapi.ComplianceLevel0Name,
+	"http://iiif.io/api/image/2/level1.json": iiifimageapi.ComplianceLevel1Name,
+	"http://iiif.io/api/image/2/level2.json": iiifimageapi.ComplianceLevel2Name,
+}
+
+var v2FeatureNames = map[string]iiifimageapi.FeatureName{
+	"baseUriRedirect":     iiifimageapi.FeatureNameBaseUriRedirect,
+	"canonicalLinkHeader": iiifimageapi.FeatureNameCanonicalLinkHeader,
+	"cors":                iiifimageapi.FeatureNameCors,
+	"jsonldMediaType":     iiifimageapi.FeatureNameJsonldMediaType,
+	"mirroring":           iiifimageapi.FeatureNameMirroring,
+	"profileLinkHeader":   iiifimageapi.FeatureNameProfileLinkHeader,
+	"regionByPct":         iiifimageapi.FeatureNameRegionByPct,
+	"regionByPx":          iiifimageapi.FeatureNameRegionByPx,
+	"regionSquare":        iiifimageapi.FeatureNameRegionSquare,
+	"rotationArbitrary":   iiifimageapi.FeatureNameRotationArbitrary,
+	"rotationBy90s":       iiifimageapi.FeatureNameRotationBy90s,
+	"sizeAboveFull":       iiifimageapi.FeatureNameSizeUpscaling,
+	"sizeByConfinedWh":    iiifimageapi.FeatureNameSizeByConfinedWh,
+	"sizeByDistortedWh":   iiifimageapi.FeatureNameSizeByWh,
+	"sizeByForcedWh":      iiifimageapi.FeatureNameSizeByWh,
+	"sizeByH":             iiifimageapi.FeatureNameSizeByH,
+	"sizeByPct":           iiifimageapi.FeatureNameSizeByPct,
+	"sizeByW":             iiifimageapi.FeatureNameSizeByW,
+	"sizeByWh":            iiifimageapi.FeatureNameSizeByWh,
+}
+
+// ConvertImageInformationV2 converts a 2.x document to its 3.0 equivalent with the given id. Capabilities of the
+// profile which are not part of the official 3.0 compliance level are listed as extras, and any which have no 3.0
+// equivalent (e.g. sizeByWhListed) are dropped.
+func ConvertImageInformationV2(v2 ImageInformationV2, id string) (iiifimageapi.ImageInformation, error) {
+	profile, ok := v2ComplianceLevels[v2.Profile.ComplianceLevel]
+	if !ok {
+		return iiifimageapi.ImageInformation{}, fmt.Errorf("profile: unsupported compliance level: %s", v2.Profile.ComplianceLevel)
+	} else if v2.Width == 0 || v2.Height == 0 {
+		return iiifimageapi.ImageInformation{}, errors.New("width and height must not be 0")
+	}
+
+	spec := iiifimageapi.OfficialComplianceLevels[profile]
+
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      id,
+		Profile: profile,
+		Width:   v2.Width,
+		Height:  v2.Height,
+		Sizes:   v2.Sizes,
+		Tiles:   v2.Tiles,
+	})
+
+	if license, ok := v2.License.(string); ok {
+		info.Rights = license
+	}
+
+	baseFeatures := map[iiifimageapi.FeatureName]struct{}{}
+
+	for _, feature := range spec.BaseFeatures() {
+		baseFeatures[feature] = struct{}{}
+	}
+
+	extraFeatures := map[iiifimageapi.FeatureName]struct{}{}
+
+	for _, extension := range v2.Profile.Extensions {
+		info.MaxWidth = uint32ptr.Min(info.MaxWidth, extension.MaxWidth)
+		info.MaxHeight = uint32ptr.Min(info.MaxHeight, extension.MaxHeight)
+		info.MaxArea = uint32ptr.Min(info.MaxArea, extension.MaxArea)
+
+		info.ExtraFormats = appendMissingStrings(info.ExtraFormats, spec.BaseFormats(), extension.Formats)
+		info.ExtraQualities = appendMissingStrings(info.ExtraQualities, append([]string{"default"}, spec.BaseQualities()...), extension.Qualities)
+
+		for _, v2Feature := range extension.Supports {
+			feature, ok := v2FeatureNames[v2Feature]
+			if !ok {
+				continue
+			} else if _, ok := baseFeatures[feature]; ok {
+				continue
+			}
+
+			extraFeatures[feature] = struct{}{}
+		}
+	}
+
+	for feature := range extraFeatures {
+		info.ExtraFeatures = append(info.ExtraFeatures, feature)
+	}
+
+	info.ExtraFeatures.Sort()
+
+	return info, nil
+}
+
+// MigrationOptions contains the properties which affect how a 2.x tree is migrated.
+type MigrationOptions struct {
+	// ID is used for the id of the migrated image. If empty, the `@id` of the 2.x document is used.
+	ID string
+
+	// DefaultQuality is the quality which "default" images represent. If empty, "color" is used.
+	DefaultQuality string
+}
+
+// MigratedFile is a single image of a 2.x tree and its 3.0 canonical path.
+type MigratedFile struct {
+	From string
+	To   string
+}
+
+// Migration is the result of [PlanMigration].
+type Migration struct {
+	// SourceID is the `@id` of the 2.x document.
+	SourceID string
+
+	// ImageInformation is the converted info.json document.
+	ImageInformation iiifimageapi.ImageInformation
+
+	// Files lists every image which is migrated, sorted by its original path. Images whose path is already canonical
+	// are included with the same From and To.
+	Files []MigratedFile
+
+	// Warnings describe files which could not be migrated.
+	Warnings []string
+}
+
+// Redirect maps an original URL to its 3.0 canonical URL.
+type Redirect struct {
+	From string
+	To   string
+}
+
+// Redirects lists the original URLs which changed. URLs are relative to the image if the ids are empty.
+func (m Migration) Redirects() []Redirect {
+	var redirects []Redirect
+
+	if m.SourceID != m.ImageInformation.ID {
+		redirects = append(redirects, Redirect{
+			From: joinURL(m.SourceID, InfoPath),
+			To:   joinURL(m.ImageInformation.ID, InfoPath),
+		})
+	}
+
+	for _, file := range m.Files {
+		from := joinURL(m.SourceID, file.From)
+		to := joinURL(m.ImageInformation.ID, file.To)
+
+		if from == to {
+			continue
+		}
+
+		redirects = append(redirects, Redirect{
+			From: from,
+			To:   to,
+		})
+	}
+
+	return redirects
+}
+
+// WriteRedirects writes one `{from} {to}` line per redirect, which is compatible with common web server map files.
+func WriteRedirects(w io.Writer, redirects []Redirect) error {
+	for _, redirect := range redirects {
+		_, err := fmt.Fprintf(w, "%s %s\n", redirect.From, redirect.To)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PlanMigration reads the 2.x info.json document of fsys and maps each of its images to the 3.0 canonical path of
+// the same request. Conversions rely on [imagerequest.ParsedParams.Resolve] and [imagerequest.ResolvedParams.Canonical]
+// of the converted document, so a `full` size becomes `max` or `w,h`, and `w,` sizes become `w,h`.
+func PlanMigration(fsys fs.FS, opts MigrationOptions) (Migration, error) {
+	infoBytes, err := fs.ReadFile(fsys, InfoPath)
+	if err != nil {
+		return Migration{}, fmt.Errorf("reading %s: %v", InfoPath, err)
+	}
+
+	var v2 ImageInformationV2
+
+	err = json.Unmarshal(infoBytes, &v2)
+	if err != nil {
+		return Migration{}, fmt.Errorf("decoding %s: %v", InfoPath, err)
+	} else if v2.Context != ImageInformationV2Context {
+		return Migration{}, fmt.Errorf("decoding %s: unexpected context: %s", InfoPath, v2.Context)
+	}
+
+	id := opts.ID
+	if id == "" {
+		id = v2.ID
+	}
+
+	info, err := ConvertImageInformationV2(v2, id)
+	if err != nil {
+		return Migration{}, fmt.Errorf("converting %s: %v", InfoPath, err)
+	}
+
+	defaultQuality := opts.DefaultQuality
+	if defaultQuality == "" {
+		defaultQuality = "color"
+	}
+
+	migration := Migration{
+		SourceID:         v2.ID,
+		ImageInformation: info,
+	}
+
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation:     info,
+		DefaultQuality:       defaultQuality,
+		IgnoreFeatureErrors:  true,
+		IgnoreMaxConstraints: true,
+	}
+
+	targets := map[string]string{}
+
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() || path == InfoPath || path == ManifestPath {
+			return nil
+		}
+
+		to, err := migrateV2Path(path, resolveOptions)
+		if err != nil {
+			migration.Warnings = append(migration.Warnings, fmt.Sprintf("%s: ignored: %v", path, err))
+
+			return nil
+		} else if previous, ok := targets[to]; ok {
+			migration.Warnings = append(migration.Warnings, fmt.Sprintf("%s: ignored: same canonical path as %s", path, previous))
+
+			return nil
+		}
+
+		targets[to] = path
+		migration.Files = append(migration.Files, MigratedFile{
+			From: path,
+			To:   to,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return Migration{}, err
+	}
+
+	sort.Slice(migration.Files, func(i, j int) bool {
+		return migration.Files[i].From < migration.Files[j].From
+	})
+
+	return migration, nil
+}
+
+func migrateV2Path(path string, resolveOptions imagerequest.ResolveOptions) (string, error) {
+	raw, err := imagerequest.RawParamsFromString(path)
+	if err != nil {
+		return "", err
+	}
+
+	if raw[1] == "full" {
+		// in 2.x this was always the size of the region, regardless of max constraints
+		raw[1] = "max"
+	}
+
+	parsed, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := parsed.Resolve(resolveOptions)
+	if err != nil {
+		return "", err
+	}
+
+	return resolved.Canonical().String(), nil
+}
+
+// MigrationMode describes how files are transferred by [ApplyMigration].
+type MigrationMode string
+
+const (
+	MigrationModeCopy     MigrationMode = "copy"
+	MigrationModeMove     MigrationMode = "move"
+	MigrationModeHardlink MigrationMode = "hardlink"
+)
+
+// ApplyMigration transfers the files of m from srcDir to dstDir and writes the converted info.json document to dstDir.
+// The directories may be the same to migrate a tree in place, in which case the 2.x info.json document is replaced.
+// Directories emptied by [MigrationModeMove] are removed.
+//
+// Nothing is transferred if any destination would replace an existing file or another original, or if two originals
+// share a destination, so a conflict never leaves the tree partially migrated.
+func ApplyMigration(srcDir, dstDir string, m Migration, mode MigrationMode) error {
+	var transfer func(src, dst string) error
+
+	switch mode {
+	case MigrationModeCopy:
+		transfer = copyFile
+	case MigrationModeMove:
+		transfer = os.Rename
+	case MigrationModeHardlink:
+		transfer = os.Link
+	default:
+		return fmt.Errorf("unsupported migration mode: %s", mode)
+	}
+
+	sameDir := filepath.Clean(srcDir) == filepath.Clean(dstDir)
+
+	err := checkMigrationConflicts(dstDir, m, sameDir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range m.Files {
+		if sameDir && file.From == file.To {
+			continue
+		}
+
+		dst := filepath.Join(dstDir, filepath.FromSlash(file.To))
+
+		err := os.MkdirAll(filepath.Dir(dst), 0o755)
+		if err != nil {
+			return err
+		}
+
+		err = transfer(filepath.Join(srcDir, filepath.FromSlash(file.From)), dst)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.From, err)
+		}
+	}
+
+	if mode == MigrationModeMove {
+		for _, file := range m.Files {
+			removeEmptyParents(srcDir, file.From)
+		}
+	}
+
+	infoBytes, err := json.MarshalIndent(m.ImageInformation, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dstDir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dstDir, InfoPath), append(infoBytes, '\n'), 0o644)
+}
+
+func checkMigrationConflicts(dstDir string, m Migration, sameDir bool) error {
+	sources := map[string]struct{}{}
+
+	for _, file := range m.Files {
+		sources[file.From] = struct{}{}
+	}
+
+	destinations := map[string]string{}
+
+	for _, file := range m.Files {
+		if sameDir && file.From == file.To {
+			continue
+		}
+
+		if from, ok := destinations[file.To]; ok {
+			return fmt.Errorf("%s: canonical path %s is also the canonical path of %s", file.From, file.To, from)
+		}
+
+		destinations[file.To] = file.From
+
+		if _, ok := sources[file.To]; ok && sameDir {
+			return fmt.Errorf("%s: canonical path %s is also an original path", file.From, file.To)
+		}
+
+		_, err := os.Lstat(filepath.Join(dstDir, filepath.FromSlash(file.To)))
+		if err == nil {
+			return fmt.Errorf("%s: canonical path %s already exists", file.From, file.To)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+
+		return err
+	}
+
+	return out.Close()
+}
+
+func removeEmptyParents(dir, file string) {
+	for parent := path.Dir(file); parent != "."; parent = path.Dir(parent) {
+		if os.Remove(filepath.Join(dir, filepath.FromSlash(parent))) != nil {
+			// not empty (or already removed)
+			return
+		}
+	}
+}
+
+func appendMissingStrings(extra, base, values []string) []string {
+	known := map[string]struct{}{}
+
+	for _, value := range append(append([]string(nil), base...), extra...) {
+		known[value] = struct{}{}
+	}
+
+	for _, value := range values {
+		if _, ok := known[value]; ok {
+			continue
+		}
+
+		known[value] = struct{}{}
+		extra = append(extra, value)
+	}
+
+	return extra
+}
+
+func joinURL(base, path string) string {
+	if base == "" {
+		return path
+	}
+
+	return strings.TrimSuffix(base, "/") + "/" + path
+}
@@ -0,0 +1,263 @@
+package static
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+const exampleInfoV2 = `{
+  "@context": "http://iiif.io/api/image/2/context.json",
+  "@id": "https://example.com/iiif/2/example",
+  "protocol": "http://iiif.io/api/image",
+  "width": 100,
+  "height": 60,
+  "sizes": [{"width": 50, "height": 30}],
+  "tiles": [{"width": 64, "scaleFactors": [1, 2]}],
+  "profile": [
+    "http://iiif.io/api/image/2/level0.json",
+    {"formats": ["jpg", "png"], "qualities": ["default", "gray"], "supports": ["cors", "sizeByWhListed"]}
+  ]
+}`
+
+func exampleMigrationFS() fstest.MapFS {
+	return fstest.MapFS{
+		"info.json":                       &fstest.MapFile{Data: []byte(exampleInfoV2)},
+		"full/full/0/default.jpg":         &fstest.MapFile{Data: []byte("full")},
+		"full/50,/0/default.jpg":          &fstest.MapFile{Data: []byte("50")},
+		"full/50,30/0/default.png":        &fstest.MapFile{Data: []byte("50png")},
+		"0,0,64,60/64,/0/default.jpg":     &fstest.MapFile{Data: []byte("tile1")},
+		"64,0,36,60/36,/0/default.jpg":    &fstest.MapFile{Data: []byte("tile2")},
+		"full/max/0/default.jpg":          &fstest.MapFile{Data: []byte("max")},
+		"full/50,/0/unknown.jpg":          &fstest.MapFile{Data: []byte("unknown")},
+		"full/50,/0/default.jpg.orig.bak": &fstest.MapFile{Data: []byte("garbage")},
+	}
+}
+
+func TestConvertImageInformationV2(t *testing.T) {
+	migration, err := PlanMigration(exampleMigrationFS(), MigrationOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	info := migration.ImageInformation
+
+	if _e, _a := "https://example.com/iiif/2/example", info.ID; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.Context, info.Context; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := iiifimageapi.ComplianceLevel0Name, info.Profile; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"png"}, info.ExtraFormats; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []string{"gray"}, info.ExtraQualities; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (iiifimageapi.FeatureNameList{iiifimageapi.FeatureNameCors}), info.ExtraFeatures; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 1, len(info.Tiles); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestPlanMigration(t *testing.T) {
+	migration, err := PlanMigration(exampleMigrationFS(), MigrationOptions{
+		ID: "https://example.com/iiif/3/example",
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	expected := []MigratedFile{
+		{From: "0,0,64,60/64,/0/default.jpg", To: "0,0,64,60/64,60/0/default.jpg"},
+		{From: "64,0,36,60/36,/0/default.jpg", To: "64,0,36,60/36,60/0/default.jpg"},
+		{From: "full/50,/0/default.jpg", To: "full/50,30/0/default.jpg"},
+		{From: "full/50,30/0/default.png", To: "full/50,30/0/default.png"},
+		{From: "full/full/0/default.jpg", To: "full/100,60/0/default.jpg"},
+	}
+
+	if _e, _a := expected, migration.Files; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	warnings := strings.Join(migration.Warnings, "\n")
+
+	for _, expected := range []string{
+		"full/50,/0/default.jpg.orig.bak: ignored",
+		"full/50,/0/unknown.jpg: ignored",
+		"full/max/0/default.jpg: ignored: same canonical path as full/full/0/default.jpg",
+	} {
+		if !strings.Contains(warnings, expected) {
+			t.Fatalf("expected `%v` to contain `%v`", warnings, expected)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+
+	err = WriteRedirects(buf, migration.Redirects())
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if _e, _a := 6, len(lines); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/2/example/info.json https://example.com/iiif/3/example/info.json", lines[0]; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "https://example.com/iiif/2/example/full/full/0/default.jpg https://example.com/iiif/3/example/full/100,60/0/default.jpg", lines[5]; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func writeMigrationDir(t *testing.T, fsys fstest.MapFS) string {
+	dir := t.TempDir()
+
+	for path, file := range fsys {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+
+		err := os.MkdirAll(filepath.Dir(fullPath), 0o755)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+
+		err = os.WriteFile(fullPath, file.Data, 0o644)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestApplyMigration_MoveInPlace(t *testing.T) {
+	dir := writeMigrationDir(t, exampleMigrationFS())
+
+	migration, err := PlanMigration(os.DirFS(dir), MigrationOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	err = ApplyMigration(dir, dir, migration, MigrationModeMove)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "full", "100,60", "0", "default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "full", string(data); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "full", "full")); !os.IsNotExist(err) {
+		t.Fatalf("expected not exist error but got: %v", err)
+	} else if _, err := os.Stat(filepath.Join(dir, "0,0,64,60", "64,")); !os.IsNotExist(err) {
+		t.Fatalf("expected not exist error but got: %v", err)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "info.json"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := `"type": "ImageService3"`, string(data); !strings.Contains(_a, _e) {
+		t.Fatalf("expected `%v` to contain `%v`", _a, _e)
+	}
+
+	report, err := Verify(os.DirFS(dir), VerifyOptions{IgnoreDimensions: true})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := []string{}, report.Missing; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestApplyMigration_Hardlink(t *testing.T) {
+	srcDir := writeMigrationDir(t, exampleMigrationFS())
+	dstDir := filepath.Join(t.TempDir(), "v3")
+
+	migration, err := PlanMigration(os.DirFS(srcDir), MigrationOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	err = ApplyMigration(srcDir, dstDir, migration, MigrationModeHardlink)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	srcStat, err := os.Stat(filepath.Join(srcDir, "full", "50,", "0", "default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	dstStat, err := os.Stat(filepath.Join(dstDir, "full", "50,30", "0", "default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if !os.SameFile(srcStat, dstStat) {
+		t.Fatal("expected same file")
+	}
+}
+
+func TestApplyMigration_Conflicts(t *testing.T) {
+	srcDir := writeMigrationDir(t, exampleMigrationFS())
+	dstDir := writeMigrationDir(t, fstest.MapFS{
+		"full/100,60/0/default.jpg": &fstest.MapFile{Data: []byte("existing")},
+	})
+
+	migration, err := PlanMigration(os.DirFS(srcDir), MigrationOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	for _, mode := range []MigrationMode{MigrationModeCopy, MigrationModeMove, MigrationModeHardlink} {
+		err = ApplyMigration(srcDir, dstDir, migration, mode)
+		if err == nil {
+			t.Fatalf("%s: expected error but got none", mode)
+		} else if _e, _a := "already exists", err.Error(); !strings.Contains(_a, _e) {
+			t.Fatalf("%s: expected `%v` to contain `%v`", mode, _a, _e)
+		}
+
+		// nothing was transferred, even before the conflict
+		if _, err := os.Stat(filepath.Join(dstDir, "0,0,64,60")); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected not exist error but got: %v", mode, err)
+		} else if _, err := os.Stat(filepath.Join(srcDir, "0,0,64,60", "64,", "0", "default.jpg")); err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", mode, err)
+		}
+	}
+
+	// in place, a destination must not replace an original which has not been transferred yet
+	err = ApplyMigration(srcDir, srcDir, Migration{
+		ImageInformation: migration.ImageInformation,
+		Files: []MigratedFile{
+			{From: "full/full/0/default.jpg", To: "full/50,/0/default.jpg"},
+			{From: "full/50,/0/default.jpg", To: "full/50,30/0/default.jpg"},
+		},
+	}, MigrationModeCopy)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	data, err := os.ReadFile(filepath.Join(srcDir, "full", "50,", "0", "default.jpg"))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := "50", string(data); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	// two originals must not share a destination
+	err = ApplyMigration(srcDir, t.TempDir(), Migration{
+		ImageInformation: migration.ImageInformation,
+		Files: []MigratedFile{
+			{From: "full/full/0/default.jpg", To: "full/100,60/0/default.jpg"},
+			{From: "full/max/0/default.jpg", To: "full/100,60/0/default.jpg"},
+		},
+	}, MigrationModeCopy)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
@@ -0,0 +1,275 @@
+package static
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Output receives the files of an export. Implementations must be safe for concurrent use.
+type Output interface {
+	// WriteFile stores the content produced by write at the slash-separated path.
+	WriteFile(path string, write func(w io.Writer) error) error
+
+	// Close finishes the output. It does not close any underlying writer.
+	Close() error
+}
+
+//
+
+// DirOutput writes files into a directory. Files are written to a temporary file before being renamed, so interrupted
+// exports never leave partial files behind. It implements [fs.StatFS] for existing files.
+type DirOutput struct {
+	dir string
+}
+
+var _ Output = DirOutput{}
+var _ fs.StatFS = DirOutput{}
+
+func NewDirOutput(dir string) DirOutput {
+	return DirOutput{
+		dir: dir,
+	}
+}
+
+func (o DirOutput) WriteFile(path string, write func(w io.Writer) error) error {
+	path = filepath.Join(o.dir, filepath.FromSlash(path))
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	err = write(f)
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(f.Name(), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (o DirOutput) Open(name string) (fs.File, error) {
+	return os.DirFS(o.dir).Open(name)
+}
+
+func (o DirOutput) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(os.DirFS(o.dir), name)
+}
+
+func (o DirOutput) Close() error {
+	return nil
+}
+
+//
+
+// ZipOutput writes files into a zip archive. Files are stored without compression since image formats are typically
+// compressed already.
+type ZipOutput struct {
+	mu      sync.Mutex
+	w       *zip.Writer
+	modTime time.Time
+}
+
+var _ Output = &ZipOutput{}
+
+func NewZipOutput(w io.Writer) *ZipOutput {
+	return &ZipOutput{
+		w:       zip.NewWriter(w),
+		modTime: time.Now(),
+	}
+}
+
+func (o *ZipOutput) WriteFile(path string, write func(w io.Writer) error) error {
+	buf := &bytes.Buffer{}
+
+	err := write(buf)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	fw, err := o.w.CreateHeader(&zip.FileHeader{
+		Name:     path,
+		Method:   zip.Store,
+		Modified: o.modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(fw)
+
+	return err
+}
+
+func (o *ZipOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.w.Close()
+}
+
+//
+
+// TarOutput writes files into a tar archive, optionally compressed with gzip.
+type TarOutput struct {
+	mu      sync.Mutex
+	gz      *gzip.Writer
+	w       *tar.Writer
+	modTime time.Time
+}
+
+var _ Output = &TarOutput{}
+
+func NewTarOutput(w io.Writer, gzipped bool) *TarOutput {
+	o := &TarOutput{
+		modTime: time.Now(),
+	}
+
+	if gzipped {
+		o.gz = gzip.NewWriter(w)
+		w = o.gz
+	}
+
+	o.w = tar.NewWriter(w)
+
+	return o
+}
+
+func (o *TarOutput) WriteFile(path string, write func(w io.Writer) error) error {
+	buf := &bytes.Buffer{}
+
+	err := write(buf)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err = o.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path,
+		Mode:     0o644,
+		Size:     int64(buf.Len()),
+		ModTime:  o.modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(o.w)
+
+	return err
+}
+
+func (o *TarOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	err := o.w.Close()
+	if err != nil {
+		return err
+	}
+
+	if o.gz != nil {
+		return o.gz.Close()
+	}
+
+	return nil
+}
+
+//
+
+// MemoryOutput keeps files in memory, typically for tests.
+type MemoryOutput struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+var _ Output = &MemoryOutput{}
+
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{
+		files: map[string][]byte{},
+	}
+}
+
+func (o *MemoryOutput) WriteFile(path string, write func(w io.Writer) error) error {
+	buf := &bytes.Buffer{}
+
+	err := write(buf)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.files[path] = buf.Bytes()
+
+	return nil
+}
+
+func (o *MemoryOutput) Close() error {
+	return nil
+}
+
+// Files returns a copy of the written files, keyed by path.
+func (o *MemoryOutput) Files() map[string][]byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	out := map[string][]byte{}
+
+	for path, data := range o.files {
+		out[path] = data
+	}
+
+	return out
+}
+
+// Paths returns the sorted paths of the written files.
+func (o *MemoryOutput) Paths() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var paths []string
+
+	for path := range o.files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
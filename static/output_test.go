@@ -0,0 +1,123 @@
+package static
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func exportExample(t *testing.T, out Output) ExportResult {
+	result, err := Export(context.Background(), exampleSourceImage(), out, ExportOptions{
+		ImageInformation: exampleImageInformation(),
+		Workers:          4,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	err = out.Close()
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	return result
+}
+
+func TestMemoryOutput_Manifest(t *testing.T) {
+	out := NewMemoryOutput()
+	result := exportExample(t, out)
+	files := out.Files()
+
+	if _e, _a := 14, len(files); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	var manifest Manifest
+
+	if err := json.Unmarshal(files[ManifestPath], &manifest); err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := result.Manifest, manifest; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	for _, file := range manifest.Files {
+		data, ok := files[file.Path]
+		if !ok {
+			t.Fatalf("expected file `%v`", file.Path)
+		}
+
+		sum := sha256.Sum256(data)
+
+		if _e, _a := int64(len(data)), file.Size; _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		} else if _e, _a := hex.EncodeToString(sum[:]), file.SHA256; _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+	}
+}
+
+func TestZipOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exportExample(t, NewZipOutput(buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	names := map[string]struct{}{}
+
+	for _, f := range zr.File {
+		names[f.Name] = struct{}{}
+	}
+
+	if _e, _a := 14, len(names); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _, ok := names["full/50,30/0/default.jpg"]; !ok {
+		t.Fatal("expected size image in archive")
+	}
+}
+
+func TestTarOutput_Gzip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exportExample(t, NewTarOutput(buf, true))
+
+	gz, err := gzip.NewReader(buf)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	tr := tar.NewReader(gz)
+
+	var count int
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		} else if _e, _a := hdr.Size, int64(len(data)); _e != _a {
+			t.Fatalf("expected `%v` but got: %v", _e, _a)
+		}
+
+		count++
+	}
+
+	if _e, _a := 14, count; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
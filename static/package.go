@@ -0,0 +1,3 @@
+// static offers functions to export and work with pre-rendered trees of image requests, typically for level0
+// services hosted from plain file or object storage.
+package static
@@ -0,0 +1,51 @@
+package static
+
+import (
+	"fmt"
+	"sort"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// PlannedFile is a single image of a static tree.
+type PlannedFile struct {
+	// Path is the canonical `{region}/{size}/{rotation}/{quality}.{format}` path, relative to the image.
+	Path string
+
+	Value  pixelset.Value
+	Params imagerequest.ResolvedParams
+}
+
+// Plan lists every region+size advertised by the sizes and tiles of info, resolved with the "default" quality in
+// format. The result is sorted by path.
+func Plan(info iiifimageapi.ImageInformation, format, defaultQuality string) ([]PlannedFile, error) {
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation: info,
+		DefaultQuality:   defaultQuality,
+		// level0 images do not support the requests used to describe their own sizes and tiles
+		IgnoreFeatureErrors: true,
+	}
+
+	var planned []PlannedFile
+
+	for _, value := range pixelset.NewImageDomain(info).Enumerate() {
+		resolved, err := imagerequest.NewParsedParamsFromPixelset(value, "default", format).Resolve(resolveOptions)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %v: %v", value, err)
+		}
+
+		planned = append(planned, PlannedFile{
+			Path:   resolved.Canonical().String(),
+			Value:  value,
+			Params: resolved,
+		})
+	}
+
+	sort.Slice(planned, func(i, j int) bool {
+		return planned[i].Path < planned[j].Path
+	})
+
+	return planned, nil
+}
@@ -0,0 +1,439 @@
+package static
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+// ReconstructOptions contains the properties which affect how image information is reconstructed.
+type ReconstructOptions struct {
+	// ID is used for the id of the result.
+	ID string
+
+	// Width and Height may be set if the dimensions of the full image are known. Otherwise they are inferred from the
+	// extent of tiles, the dimensions of a `full/max` image, or the largest full-region size (with a warning).
+	Width  uint32
+	Height uint32
+}
+
+// ReconstructResult is the result of [Reconstruct].
+type ReconstructResult struct {
+	// ImageInformation is a level0 document whose [pixelset.ImageDomain] covers the images which were recognized.
+	ImageInformation iiifimageapi.ImageInformation
+
+	// Warnings describe files which were ignored or which do not fit the sizes and tiles of the result.
+	Warnings []string
+}
+
+type reconstructEntry struct {
+	path   string
+	parsed imagerequest.ParsedParams
+}
+
+// Reconstruct infers the dimensions, sizes, and tiles of an image from an existing tree of
+// `{region}/{size}/{rotation}/{quality}.{format}` files. Only "default" quality, unrotated images with pixel or full
+// regions are considered. Scale factors whose tile grid is incomplete are not advertised.
+func Reconstruct(fsys fs.FS, opts ReconstructOptions) (ReconstructResult, error) {
+	var result ReconstructResult
+	var entries []reconstructEntry
+
+	formats := map[string]int{}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() || path == InfoPath || path == ManifestPath {
+			return nil
+		}
+
+		raw, err := imagerequest.RawParamsFromString(path)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: %v", path, err))
+
+			return nil
+		}
+
+		parsed, err := imagerequest.ParseRawParams(raw)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: %v", path, err))
+
+			return nil
+		} else if parsed.RegionIsPercent || (parsed.RegionIsEnum && parsed.RegionEnum != "full") {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: region is not full or pixels", path))
+
+			return nil
+		} else if parsed.SizeIsPercent || parsed.SizeIsConfined || parsed.SizeIsUpscaled {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: size is not max or pixels", path))
+
+			return nil
+		} else if parsed.RotationIsMirrored || parsed.RotationAmount != 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: image is rotated", path))
+
+			return nil
+		} else if parsed.Quality != "default" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: quality is not default", path))
+
+			return nil
+		}
+
+		formats[parsed.Format]++
+		entries = append(entries, reconstructEntry{
+			path:   path,
+			parsed: parsed,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return ReconstructResult{}, err
+	} else if len(entries) == 0 {
+		return ReconstructResult{}, errors.New("no images were found")
+	}
+
+	imageSize, warning, err := reconstructImageSize(fsys, entries, opts)
+	if err != nil {
+		return ReconstructResult{}, err
+	} else if warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+
+	info := iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      opts.ID,
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   imageSize[0],
+		Height:  imageSize[1],
+	})
+
+	for _, format := range sortedFormats(formats) {
+		if format == "jpg" {
+			continue
+		}
+
+		info.ExtraFormats = append(info.ExtraFormats, format)
+	}
+
+	if len(formats) > 0 {
+		info.PreferredFormats = []string{sortedFormats(formats)[0]}
+	}
+
+	values := map[pixelset.Value]string{}
+	sizes := map[pixelset.Value]struct{}{}
+
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation: iiifimageapi.ImageInformation{
+			Profile:      iiifimageapi.ComplianceLevel2Name,
+			Width:        imageSize[0],
+			Height:       imageSize[1],
+			ExtraFormats: info.ExtraFormats,
+		},
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	}
+
+	for _, entry := range entries {
+		resolved, err := entry.parsed.Resolve(resolveOptions)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: %v", entry.path, err))
+
+			continue
+		}
+
+		value := pixelset.Value{
+			Region: resolved.RegionPixels(),
+			Size:   resolved.SizePixels(),
+		}
+
+		if value.Region != [4]uint32{0, 0, imageSize[0], imageSize[1]} {
+			// requested regions may have been clipped to the image
+			if entry.parsed.RegionPixels[0]+entry.parsed.RegionPixels[2] > imageSize[0] || entry.parsed.RegionPixels[1]+entry.parsed.RegionPixels[3] > imageSize[1] {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: ignored: region exceeds image", entry.path))
+
+				continue
+			}
+		} else {
+			sizes[value] = struct{}{}
+		}
+
+		if _, known := values[value]; known {
+			// e.g. the same image in multiple formats
+			continue
+		}
+
+		values[value] = entry.path
+	}
+
+	tiles, tileWarnings := reconstructTiles(imageSize, values)
+	info.Tiles = tiles
+	result.Warnings = append(result.Warnings, tileWarnings...)
+
+	// a single tile of the full image (e.g. of the largest scale factor) is already advertised by the tiles
+	tileValues := map[pixelset.Value]struct{}{}
+
+	for _, value := range pixelset.NewImageDomain(iiifimageapi.ImageInformation{
+		Width:  imageSize[0],
+		Height: imageSize[1],
+		Tiles:  tiles,
+	}).Enumerate() {
+		tileValues[value] = struct{}{}
+	}
+
+	var sizeList pixelset.ValueList
+
+	for value := range sizes {
+		if _, ok := tileValues[value]; ok {
+			continue
+		}
+
+		sizeList = append(sizeList, value)
+	}
+
+	sizeList.Sort()
+
+	for _, value := range sizeList {
+		info.Sizes = append(info.Sizes, iiifimageapi.ImageInformationSize{
+			Width:  value.Size[0],
+			Height: value.Size[1],
+		})
+	}
+
+	domain := pixelset.NewImageDomain(info)
+
+	var valueList pixelset.ValueList
+
+	for value := range values {
+		valueList = append(valueList, value)
+	}
+
+	valueList.Sort()
+
+	for _, value := range valueList {
+		if !domain.Contains(value) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: does not fit a size or complete tile grid", values[value]))
+		}
+	}
+
+	result.ImageInformation = info
+
+	return result, nil
+}
+
+func reconstructImageSize(fsys fs.FS, entries []reconstructEntry, opts ReconstructOptions) ([2]uint32, string, error) {
+	if opts.Width > 0 && opts.Height > 0 {
+		return [2]uint32{opts.Width, opts.Height}, "", nil
+	}
+
+	var extent [2]uint32
+
+	for _, entry := range entries {
+		if entry.parsed.RegionIsEnum {
+			continue
+		}
+
+		extent[0] = maxUint32(extent[0], entry.parsed.RegionPixels[0]+entry.parsed.RegionPixels[2])
+		extent[1] = maxUint32(extent[1], entry.parsed.RegionPixels[1]+entry.parsed.RegionPixels[3])
+	}
+
+	if extent[0] > 0 && extent[1] > 0 {
+		return extent, "", nil
+	}
+
+	var largest [2]uint32
+
+	for _, entry := range entries {
+		if !entry.parsed.RegionIsEnum {
+			continue
+		} else if entry.parsed.SizeIsEnum {
+			size, err := decodeImageSize(fsys, entry.path)
+			if err != nil {
+				return [2]uint32{}, "", fmt.Errorf("decoding %s: %v", entry.path, err)
+			}
+
+			return size, "", nil
+		} else if entry.parsed.SizePixels[0] != nil && entry.parsed.SizePixels[1] != nil {
+			if *entry.parsed.SizePixels[0] > largest[0] {
+				largest = [2]uint32{*entry.parsed.SizePixels[0], *entry.parsed.SizePixels[1]}
+			}
+		}
+	}
+
+	if largest[0] > 0 && largest[1] > 0 {
+		return largest, fmt.Sprintf("image dimensions were assumed from the largest size (%d,%d)", largest[0], largest[1]), nil
+	}
+
+	return [2]uint32{}, "", errors.New("unable to determine image dimensions")
+}
+
+func reconstructTiles(imageSize [2]uint32, values map[pixelset.Value]string) ([]iiifimageapi.ImageInformationTile, []string) {
+	var warnings []string
+
+	// unclipped tiles reveal the tile size
+	tileSizes := map[[2]uint32]struct{}{}
+
+	for value := range values {
+		if value.Region[0]+value.Region[2] < imageSize[0] && value.Region[1]+value.Region[3] < imageSize[1] {
+			tileSizes[value.Size] = struct{}{}
+		}
+	}
+
+	if len(tileSizes) == 0 {
+		// a single row or column of tiles is clipped in one dimension; assume square
+		for value := range values {
+			if value.Region[0]+value.Region[2] < imageSize[0] {
+				tileSizes[[2]uint32{value.Size[0], value.Size[0]}] = struct{}{}
+			} else if value.Region[1]+value.Region[3] < imageSize[1] {
+				tileSizes[[2]uint32{value.Size[1], value.Size[1]}] = struct{}{}
+			}
+		}
+	}
+
+	var tiles []iiifimageapi.ImageInformationTile
+
+	for _, tileSize := range sortedSizes(tileSizes) {
+		scaleFactors := map[uint32]struct{}{}
+
+		for value := range values {
+			if value.Size[0] > tileSize[0] || value.Size[1] > tileSize[1] {
+				continue
+			}
+
+			if scaleFactor, ok := findTileScaleFactor(imageSize, tileSize, value); ok {
+				scaleFactors[scaleFactor] = struct{}{}
+			}
+		}
+
+		var completeScaleFactors []uint32
+
+		for _, scaleFactor := range sortedScaleFactors(scaleFactors) {
+			grid := pixelset.NewImageTileDomain(imageSize, iiifimageapi.ImageInformationTile{
+				Width:        tileSize[0],
+				Height:       tileSize[1],
+				ScaleFactors: []uint32{scaleFactor},
+			}).Enumerate()
+
+			var missing int
+
+			for _, value := range grid {
+				if _, known := values[value]; !known {
+					missing++
+				}
+			}
+
+			if missing > 0 {
+				warnings = append(warnings, fmt.Sprintf("tiles (%d,%d) scale factor %d: not advertised: missing %d of %d tiles", tileSize[0], tileSize[1], scaleFactor, missing, len(grid)))
+
+				continue
+			}
+
+			completeScaleFactors = append(completeScaleFactors, scaleFactor)
+		}
+
+		if len(completeScaleFactors) == 0 {
+			continue
+		}
+
+		tile := iiifimageapi.ImageInformationTile{
+			Width:        tileSize[0],
+			ScaleFactors: completeScaleFactors,
+		}
+
+		if tileSize[1] != tileSize[0] {
+			tile.Height = tileSize[1]
+		}
+
+		tiles = append(tiles, tile)
+	}
+
+	return tiles, warnings
+}
+
+func findTileScaleFactor(imageSize, tileSize [2]uint32, value pixelset.Value) (uint32, bool) {
+	// clipped tiles satisfy size = ceil(region / scaleFactor), so a few candidates need to be tried
+	lower := uint32(math.Ceil(float64(value.Region[2]) / float64(value.Size[0])))
+
+	var upper uint32
+
+	if value.Size[0] > 1 {
+		upper = uint32(math.Ceil(float64(value.Region[2])/float64(value.Size[0]-1))) - 1
+	} else {
+		upper = maxUint32(imageSize[0], imageSize[1])
+	}
+
+	for scaleFactor := lower; scaleFactor <= upper && scaleFactor > 0; scaleFactor++ {
+		domain := pixelset.NewImageTileDomain(imageSize, iiifimageapi.ImageInformationTile{
+			Width:        tileSize[0],
+			Height:       tileSize[1],
+			ScaleFactors: []uint32{scaleFactor},
+		})
+
+		if domain.Contains(value) {
+			return scaleFactor, true
+		}
+	}
+
+	return 0, false
+}
+
+func sortedFormats(formats map[string]int) []string {
+	var out []string
+
+	for format := range formats {
+		out = append(out, format)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if formats[out[i]] != formats[out[j]] {
+			return formats[out[i]] > formats[out[j]]
+		}
+
+		return out[i] < out[j]
+	})
+
+	return out
+}
+
+func sortedSizes(sizes map[[2]uint32]struct{}) [][2]uint32 {
+	var out [][2]uint32
+
+	for size := range sizes {
+		out = append(out, size)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i][0] != out[j][0] {
+			return out[i][0] < out[j][0]
+		}
+
+		return out[i][1] < out[j][1]
+	})
+
+	return out
+}
+
+func sortedScaleFactors(scaleFactors map[uint32]struct{}) []uint32 {
+	var out []uint32
+
+	for scaleFactor := range scaleFactors {
+		out = append(out, scaleFactor)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i] < out[j]
+	})
+
+	return out
+}
+
+func maxUint32(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
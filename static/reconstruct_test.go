@@ -0,0 +1,91 @@
+package static
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+)
+
+func TestReconstruct_Export(t *testing.T) {
+	fsys := exampleExportFS(t)
+	delete(fsys, InfoPath)
+
+	result, err := Reconstruct(fsys, ReconstructOptions{ID: "https://example.com/iiif/example"})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := 0, len(result.Warnings); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	info := result.ImageInformation
+
+	if _e, _a := [2]uint32{100, 60}, [2]uint32{info.Width, info.Height}; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := exampleImageInformation().Tiles, info.Tiles; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := []iiifimageapi.ImageInformationSize{{Width: 50, Height: 30}}, info.Sizes; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	expected := pixelset.NewImageDomain(exampleImageInformation()).Enumerate()
+	actual := pixelset.NewImageDomain(info)
+
+	for _, value := range expected {
+		if !actual.Contains(value) {
+			t.Fatalf("expected domain to contain `%v`", value)
+		}
+	}
+
+	if _e, _a := len(expected), len(actual.Enumerate()); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestReconstruct_IncompleteGrid(t *testing.T) {
+	fsys := exampleExportFS(t)
+	delete(fsys, InfoPath)
+	delete(fsys, "64,0,36,60/18,30/0/default.jpg")
+	fsys["notes.txt"] = &fstest.MapFile{Data: []byte("hello")}
+
+	result, err := Reconstruct(fsys, ReconstructOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := []uint32{1, 4}, result.ImageInformation.Tiles[0].ScaleFactors; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	warnings := strings.Join(result.Warnings, "\n")
+
+	for _, expected := range []string{
+		"notes.txt: ignored",
+		"scale factor 2: not advertised: missing 1 of 2 tiles",
+		"0,0,64,60/32,30/0/default.jpg: does not fit",
+	} {
+		if !strings.Contains(warnings, expected) {
+			t.Fatalf("expected `%v` to contain `%v`", warnings, expected)
+		}
+	}
+}
+
+func TestReconstruct_SizesOnly(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	for path, data := range exampleExportFS(t) {
+		if strings.HasPrefix(path, "full/") {
+			fsys[path] = data
+		}
+	}
+
+	result, err := Reconstruct(fsys, ReconstructOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := [2]uint32{50, 30}, [2]uint32{result.ImageInformation.Width, result.ImageInformation.Height}; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 1, len(result.Warnings); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
@@ -0,0 +1,161 @@
+package static
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"sort"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+)
+
+// VerifyOptions contains the properties which affect how a tree is verified.
+type VerifyOptions struct {
+	// Format is the format of the expected images. If empty, the first preferred format of the info.json is used,
+	// otherwise "jpg".
+	Format string
+
+	// IgnoreDimensions may be set to only check for the existence of files rather than decoding their dimensions.
+	IgnoreDimensions bool
+}
+
+// VerifyReport is the result of [Verify]. It is intended to be serialized for automation.
+type VerifyReport struct {
+	OK bool `json:"ok"`
+
+	// Expected is the number of images described by the info.json document.
+	Expected int `json:"expected"`
+
+	// Missing lists expected images which do not exist.
+	Missing []string `json:"missing"`
+
+	// Extra lists files which are not expected (other than info.json and manifest.json).
+	Extra []string `json:"extra"`
+
+	// Mismatched lists images whose dimensions could not be read or do not match the expected size.
+	Mismatched []VerifyMismatch `json:"mismatched"`
+}
+
+// VerifyMismatch describes an image with unexpected dimensions.
+type VerifyMismatch struct {
+	Path     string    `json:"path"`
+	Expected [2]uint32 `json:"expected"`
+	Actual   [2]uint32 `json:"actual"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Verify compares the files of fsys against the images described by its info.json document (see [Plan]).
+func Verify(fsys fs.FS, opts VerifyOptions) (VerifyReport, error) {
+	infoBytes, err := fs.ReadFile(fsys, InfoPath)
+	if err != nil {
+		return VerifyReport{}, fmt.Errorf("reading %s: %v", InfoPath, err)
+	}
+
+	var info iiifimageapi.ImageInformation
+
+	err = json.Unmarshal(infoBytes, &info)
+	if err != nil {
+		return VerifyReport{}, fmt.Errorf("decoding %s: %v", InfoPath, err)
+	}
+
+	format := opts.Format
+	if format == "" {
+		if len(info.PreferredFormats) > 0 {
+			format = info.PreferredFormats[0]
+		} else {
+			format = "jpg"
+		}
+	}
+
+	// the quality does not affect the canonical paths
+	planned, err := Plan(info, format, "color")
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	report := VerifyReport{
+		Expected:   len(planned),
+		Missing:    []string{},
+		Extra:      []string{},
+		Mismatched: []VerifyMismatch{},
+	}
+
+	expected := map[string]struct{}{}
+
+	for _, file := range planned {
+		expected[file.Path] = struct{}{}
+
+		if _, err := fs.Stat(fsys, file.Path); errors.Is(err, fs.ErrNotExist) {
+			report.Missing = append(report.Missing, file.Path)
+
+			continue
+		} else if err != nil {
+			return VerifyReport{}, err
+		}
+
+		if opts.IgnoreDimensions {
+			continue
+		}
+
+		actual, err := decodeImageSize(fsys, file.Path)
+		if err != nil {
+			report.Mismatched = append(report.Mismatched, VerifyMismatch{
+				Path:     file.Path,
+				Expected: file.Value.Size,
+				Error:    err.Error(),
+			})
+		} else if actual != file.Value.Size {
+			report.Mismatched = append(report.Mismatched, VerifyMismatch{
+				Path:     file.Path,
+				Expected: file.Value.Size,
+				Actual:   actual,
+			})
+		}
+	}
+
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() {
+			return nil
+		} else if path == InfoPath || path == ManifestPath {
+			return nil
+		} else if _, ok := expected[path]; ok {
+			return nil
+		}
+
+		report.Extra = append(report.Extra, path)
+
+		return nil
+	})
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	sort.Strings(report.Extra)
+
+	report.OK = len(report.Missing) == 0 && len(report.Extra) == 0 && len(report.Mismatched) == 0
+
+	return report, nil
+}
+
+func decodeImageSize(fsys fs.FS, path string) ([2]uint32, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return [2]uint32{}, err
+	}
+
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return [2]uint32{}, err
+	}
+
+	return [2]uint32{uint32(cfg.Width), uint32(cfg.Height)}, nil
+}
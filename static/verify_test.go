@@ -0,0 +1,69 @@
+package static
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+)
+
+func exampleExportFS(t *testing.T) fstest.MapFS {
+	out := NewMemoryOutput()
+	exportExample(t, out)
+
+	fsys := fstest.MapFS{}
+
+	for path, data := range out.Files() {
+		fsys[path] = &fstest.MapFile{Data: data}
+	}
+
+	return fsys
+}
+
+func TestVerify_Complete(t *testing.T) {
+	report, err := Verify(exampleExportFS(t), VerifyOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if !report.OK {
+		t.Fatalf("expected ok report but got: %#+v", report)
+	} else if _e, _a := 12, report.Expected; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestVerify_Incomplete(t *testing.T) {
+	fsys := exampleExportFS(t)
+
+	delete(fsys, "full/50,30/0/default.jpg")
+	fsys["full/max/0/default.jpg"] = &fstest.MapFile{Data: []byte("extra")}
+	fsys["0,0,32,32/32,32/0/default.jpg"] = fsys["full/25,15/0/default.jpg"]
+	fsys["32,0,32,32/32,32/0/default.jpg"] = &fstest.MapFile{Data: []byte("corrupt")}
+
+	report, err := Verify(fsys, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if report.OK {
+		t.Fatal("expected report to not be ok")
+	} else if _e, _a := "[full/50,30/0/default.jpg]", fmt.Sprint(report.Missing); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := "[full/max/0/default.jpg]", fmt.Sprint(report.Extra); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := 2, len(report.Mismatched); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := (VerifyMismatch{Path: "0,0,32,32/32,32/0/default.jpg", Expected: [2]uint32{32, 32}, Actual: [2]uint32{25, 15}}), report.Mismatched[0]; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if report.Mismatched[1].Error == "" {
+		t.Fatal("expected decoding error")
+	}
+}
+
+func TestVerify_IgnoreDimensions(t *testing.T) {
+	fsys := exampleExportFS(t)
+	fsys["32,0,32,32/32,32/0/default.jpg"] = &fstest.MapFile{Data: []byte("corrupt")}
+
+	report, err := Verify(fsys, VerifyOptions{IgnoreDimensions: true})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if !report.OK {
+		t.Fatalf("expected ok report but got: %#+v", report)
+	}
+}
@@ -0,0 +1,3 @@
+// stitch offers functions to render arbitrary image requests from the tiles of a grid, which allows level0 services and
+// static trees to be used as though they support any region and size.
+package stitch
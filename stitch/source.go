@@ -0,0 +1,67 @@
+package stitch
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+
+	"github.com/dpb587/go-iiif-image-api-v3/client"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+)
+
+// TileSource provides the images of individual tiles.
+type TileSource interface {
+	// Tile returns the decoded image of params, which are always resolved from a region+size of the tile grid.
+	Tile(ctx context.Context, params imagerequest.ResolvedParams) (image.Image, error)
+}
+
+// TileSourceFunc is a function which implements [TileSource].
+type TileSourceFunc func(ctx context.Context, params imagerequest.ResolvedParams) (image.Image, error)
+
+var _ TileSource = TileSourceFunc(nil)
+
+func (f TileSourceFunc) Tile(ctx context.Context, params imagerequest.ResolvedParams) (image.Image, error) {
+	return f(ctx, params)
+}
+
+// FSTileSource reads tiles from the canonical paths of a static tree (e.g. as exported by the static package).
+type FSTileSource struct {
+	FS fs.FS
+}
+
+var _ TileSource = FSTileSource{}
+
+func (s FSTileSource) Tile(_ context.Context, params imagerequest.ResolvedParams) (image.Image, error) {
+	f, err := s.FS.Open(params.Canonical().String())
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+
+	return img, err
+}
+
+// ServiceTileSource downloads tiles from a remote service.
+type ServiceTileSource struct {
+	Service *client.Service
+}
+
+var _ TileSource = ServiceTileSource{}
+
+func (s ServiceTileSource) Tile(ctx context.Context, params imagerequest.ResolvedParams) (image.Image, error) {
+	res, err := s.Service.Download(ctx, params.Canonical())
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(res.Data))
+
+	return img, err
+}
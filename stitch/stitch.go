@@ -0,0 +1,274 @@
+package stitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sync"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/pixelset"
+	"github.com/dpb587/go-iiif-image-api-v3/render"
+)
+
+// Stitcher renders image requests by compositing the tiles of an image.
+type Stitcher struct {
+	Source TileSource
+
+	// Format is the format of requested tiles. If empty, the first preferred format of the image is used, otherwise
+	// "jpg".
+	Format string
+
+	// Workers is the number of tiles requested concurrently. If zero, 4 is used.
+	Workers int
+
+	// Renderer is used to scale, rotate, and transform the composited region.
+	Renderer render.Renderer
+}
+
+func (s Stitcher) getFormat(info iiifimageapi.ImageInformation) string {
+	if s.Format != "" {
+		return s.Format
+	} else if len(info.PreferredFormats) > 0 {
+		return info.PreferredFormats[0]
+	}
+
+	return "jpg"
+}
+
+func (s Stitcher) getWorkers() int {
+	if s.Workers > 0 {
+		return s.Workers
+	}
+
+	return 4
+}
+
+// Stitch renders params, which must have been resolved against info, from the tiles of the grid whose scale factor
+// is closest to, without being smaller than, the requested size.
+func (s Stitcher) Stitch(ctx context.Context, info iiifimageapi.ImageInformation, params imagerequest.ResolvedParams) (image.Image, error) {
+	region := params.RegionPixels()
+
+	grid, err := SelectTileGrid(info, region, params.SizePixels())
+	if err != nil {
+		return nil, err
+	}
+
+	values := grid.Intersecting(region)
+	tiles := make([]image.Image, len(values))
+
+	resolveOptions := imagerequest.ResolveOptions{
+		ImageInformation:    info,
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	}
+
+	format := s.getFormat(info)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	sem := make(chan struct{}, s.getWorkers())
+
+	for valueIdx := range values {
+		wg.Add(1)
+
+		go func(valueIdx int) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			defer func() { <-sem }()
+
+			tile, err := s.tile(ctx, values[valueIdx], format, resolveOptions)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+
+				return
+			}
+
+			tiles[valueIdx] = tile
+		}(valueIdx)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	} else if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mosaic, origin := grid.composite(values, tiles)
+
+	// the requested region in the coordinates of the mosaic, expanded to whole pixels
+	crop := image.Rect(
+		int(math.Floor(float64(region[0]-origin[0])/float64(grid.ScaleFactor))),
+		int(math.Floor(float64(region[1]-origin[1])/float64(grid.ScaleFactor))),
+		int(math.Ceil(float64(region[0]+region[2]-origin[0])/float64(grid.ScaleFactor))),
+		int(math.Ceil(float64(region[1]+region[3]-origin[1])/float64(grid.ScaleFactor))),
+	).Intersect(mosaic.Bounds())
+
+	if crop.Empty() {
+		return nil, fmt.Errorf("region (%v) is not covered by tiles", region)
+	}
+
+	return s.Renderer.RenderRegion(mosaic.SubImage(crop), params)
+}
+
+func (s Stitcher) tile(ctx context.Context, value pixelset.Value, format string, resolveOptions imagerequest.ResolveOptions) (image.Image, error) {
+	params, err := imagerequest.NewParsedParamsFromPixelset(value, "default", format).Resolve(resolveOptions)
+	if err != nil {
+		return nil, fmt.Errorf("resolving tile %v: %v", value, err)
+	}
+
+	tile, err := s.Source.Tile(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("tile %s: %v", params.Canonical(), err)
+	}
+
+	return tile, nil
+}
+
+// TileGrid is a single scale factor of a tile configuration.
+type TileGrid struct {
+	ImageSize   [2]uint32
+	TileSize    [2]uint32
+	ScaleFactor uint32
+}
+
+// SelectTileGrid finds the tile configuration and scale factor of info with the least detail which is still at least
+// size when cropped to region. If every scale factor would need to be upscaled, the most detailed one is used.
+// Configurations with larger tiles are preferred for equal scale factors since fewer tiles are needed.
+func SelectTileGrid(info iiifimageapi.ImageInformation, region [4]uint32, size [2]uint32) (TileGrid, error) {
+	if size[0] == 0 || size[1] == 0 || region[2] == 0 || region[3] == 0 {
+		return TileGrid{}, errors.New("region and size must not be empty")
+	}
+
+	limit := math.Min(float64(region[2])/float64(size[0]), float64(region[3])/float64(size[1]))
+
+	var best, finest TileGrid
+
+	for _, tile := range info.Tiles {
+		tileSize := [2]uint32{tile.Width, tile.Height}
+		if tileSize[1] == 0 {
+			tileSize[1] = tileSize[0]
+		}
+
+		if tileSize[0] == 0 {
+			continue
+		}
+
+		for _, scaleFactor := range tile.ScaleFactors {
+			if scaleFactor == 0 {
+				continue
+			}
+
+			grid := TileGrid{
+				ImageSize:   [2]uint32{info.Width, info.Height},
+				TileSize:    tileSize,
+				ScaleFactor: scaleFactor,
+			}
+
+			if finest.ScaleFactor == 0 || grid.preferredTo(finest, true) {
+				finest = grid
+			}
+
+			if float64(scaleFactor) > limit+1e-9 {
+				continue
+			} else if best.ScaleFactor == 0 || grid.preferredTo(best, false) {
+				best = grid
+			}
+		}
+	}
+
+	if best.ScaleFactor != 0 {
+		return best, nil
+	} else if finest.ScaleFactor != 0 {
+		return finest, nil
+	}
+
+	return TileGrid{}, errors.New("image information does not describe any tiles")
+}
+
+func (g TileGrid) preferredTo(other TileGrid, finer bool) bool {
+	if g.ScaleFactor != other.ScaleFactor {
+		return (g.ScaleFactor < other.ScaleFactor) == finer
+	}
+
+	return g.TileSize[0]*g.TileSize[1] > other.TileSize[0]*other.TileSize[1]
+}
+
+// Intersecting lists the tiles of the grid which intersect region, ordered by row and then column.
+func (g TileGrid) Intersecting(region [4]uint32) pixelset.ValueList {
+	strideX := g.TileSize[0] * g.ScaleFactor
+	strideY := g.TileSize[1] * g.ScaleFactor
+
+	var values pixelset.ValueList
+
+	for y := region[1] / strideY * strideY; y < region[1]+region[3] && y < g.ImageSize[1]; y += strideY {
+		for x := region[0] / strideX * strideX; x < region[0]+region[2] && x < g.ImageSize[0]; x += strideX {
+			w := strideX
+			if x+w > g.ImageSize[0] {
+				w = g.ImageSize[0] - x
+			}
+
+			h := strideY
+			if y+h > g.ImageSize[1] {
+				h = g.ImageSize[1] - y
+			}
+
+			values = append(values, pixelset.Value{
+				Region: [4]uint32{x, y, w, h},
+				Size: [2]uint32{
+					uint32(math.Ceil(float64(w) / float64(g.ScaleFactor))),
+					uint32(math.Ceil(float64(h) / float64(g.ScaleFactor))),
+				},
+			})
+		}
+	}
+
+	return values
+}
+
+// composite draws tiles, which must be ordered by [TileGrid.Intersecting], into a single image at the resolution of
+// the grid. The full-resolution position of its origin is also returned.
+func (g TileGrid) composite(values pixelset.ValueList, tiles []image.Image) (*image.RGBA, [2]uint32) {
+	origin := [2]uint32{values[0].Region[0], values[0].Region[1]}
+	last := values[len(values)-1]
+
+	mosaic := image.NewRGBA(image.Rect(
+		0,
+		0,
+		int((last.Region[0]-origin[0])/g.ScaleFactor+last.Size[0]),
+		int((last.Region[1]-origin[1])/g.ScaleFactor+last.Size[1]),
+	))
+
+	for valueIdx, value := range values {
+		at := image.Pt(
+			int((value.Region[0]-origin[0])/g.ScaleFactor),
+			int((value.Region[1]-origin[1])/g.ScaleFactor),
+		)
+
+		// servers may round edge tiles differently, so draw them as-is rather than requiring the expected size
+		tile := tiles[valueIdx]
+		draw.Draw(mosaic, image.Rectangle{Min: at, Max: at.Add(tile.Bounds().Size())}, tile, tile.Bounds().Min, draw.Src)
+	}
+
+	return mosaic, origin
+}
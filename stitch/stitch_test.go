@@ -0,0 +1,229 @@
+package stitch
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	iiifimageapi "github.com/dpb587/go-iiif-image-api-v3"
+	"github.com/dpb587/go-iiif-image-api-v3/client"
+	"github.com/dpb587/go-iiif-image-api-v3/imagerequest"
+	"github.com/dpb587/go-iiif-image-api-v3/render"
+	"github.com/dpb587/go-iiif-image-api-v3/static"
+)
+
+func exampleSourceImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 2), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+
+	return img
+}
+
+func exampleImageInformation(id string) iiifimageapi.ImageInformation {
+	return iiifimageapi.NewImageInformation(iiifimageapi.ImageInformation{
+		ID:      id,
+		Profile: iiifimageapi.ComplianceLevel0Name,
+		Width:   100,
+		Height:  60,
+		Tiles: []iiifimageapi.ImageInformationTile{
+			{Width: 32, ScaleFactors: []uint32{1, 2, 4}},
+		},
+		PreferredFormats: []string{"png"},
+		ExtraFormats:     []string{"png"},
+	})
+}
+
+func exampleTreeFS(t *testing.T, info iiifimageapi.ImageInformation) fstest.MapFS {
+	out := static.NewMemoryOutput()
+
+	_, err := static.Export(context.Background(), exampleSourceImage(), out, static.ExportOptions{
+		ImageInformation: info,
+		Format:           "png",
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	fsys := fstest.MapFS{}
+
+	for path, data := range out.Files() {
+		fsys[path] = &fstest.MapFile{Data: data}
+	}
+
+	return fsys
+}
+
+func mustResolve(t *testing.T, info iiifimageapi.ImageInformation, path string) imagerequest.ResolvedParams {
+	raw, err := imagerequest.RawParamsFromString(path)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	parsed, err := imagerequest.ParseRawParams(raw)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	resolved, err := parsed.Resolve(imagerequest.ResolveOptions{
+		ImageInformation:    info,
+		DefaultQuality:      "color",
+		IgnoreFeatureErrors: true,
+	})
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	return resolved
+}
+
+func maxChannelDifference(a, b image.Image) int {
+	var max int
+
+	for y := 0; y < a.Bounds().Dy(); y++ {
+		for x := 0; x < a.Bounds().Dx(); x++ {
+			ar, ag, ab, _ := a.At(a.Bounds().Min.X+x, a.Bounds().Min.Y+y).RGBA()
+			br, bg, bb, _ := b.At(b.Bounds().Min.X+x, b.Bounds().Min.Y+y).RGBA()
+
+			for _, d := range []int{int(ar>>8) - int(br>>8), int(ag>>8) - int(bg>>8), int(ab>>8) - int(bb>>8)} {
+				if d < 0 {
+					d = -d
+				}
+
+				if d > max {
+					max = d
+				}
+			}
+		}
+	}
+
+	return max
+}
+
+func TestSelectTileGrid(t *testing.T) {
+	info := exampleImageInformation("")
+
+	for _, tc := range []struct {
+		region      [4]uint32
+		size        [2]uint32
+		scaleFactor uint32
+	}{
+		{region: [4]uint32{0, 0, 100, 60}, size: [2]uint32{100, 60}, scaleFactor: 1},
+		{region: [4]uint32{0, 0, 100, 60}, size: [2]uint32{50, 30}, scaleFactor: 2},
+		{region: [4]uint32{0, 0, 100, 60}, size: [2]uint32{40, 24}, scaleFactor: 2},
+		{region: [4]uint32{0, 0, 100, 60}, size: [2]uint32{10, 6}, scaleFactor: 4},
+		{region: [4]uint32{10, 10, 20, 20}, size: [2]uint32{40, 40}, scaleFactor: 1},
+	} {
+		grid, err := SelectTileGrid(info, tc.region, tc.size)
+		if err != nil {
+			t.Fatalf("expected `nil` but got: %v", err)
+		} else if _e, _a := tc.scaleFactor, grid.ScaleFactor; _e != _a {
+			t.Fatalf("%v/%v: expected `%v` but got: %v", tc.region, tc.size, _e, _a)
+		}
+	}
+}
+
+func TestTileGrid_Intersecting(t *testing.T) {
+	grid := TileGrid{ImageSize: [2]uint32{100, 60}, TileSize: [2]uint32{32, 32}, ScaleFactor: 2}
+
+	values := grid.Intersecting([4]uint32{70, 10, 30, 50})
+
+	if _e, _a := 1, len(values); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := [4]uint32{64, 0, 36, 60}, values[0].Region; !reflect.DeepEqual(_e, _a) {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	} else if _e, _a := [2]uint32{18, 30}, values[0].Size; _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+
+	values = grid.Intersecting([4]uint32{0, 0, 100, 60})
+
+	if _e, _a := 2, len(values); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}
+
+func TestStitcher_Stitch_FS(t *testing.T) {
+	info := exampleImageInformation("https://example.com/iiif/example")
+	fsys := exampleTreeFS(t, info)
+
+	var requests int32
+
+	stitcher := Stitcher{
+		Source: TileSourceFunc(func(ctx context.Context, params imagerequest.ResolvedParams) (image.Image, error) {
+			atomic.AddInt32(&requests, 1)
+
+			return FSTileSource{FS: fsys}.Tile(ctx, params)
+		}),
+	}
+
+	for _, tc := range []struct {
+		path      string
+		requests  int32
+		tolerance int
+	}{
+		{path: "10,20,50,30/50,30/0/default.png", requests: 4, tolerance: 0},
+		{path: "full/50,30/0/default.png", requests: 2, tolerance: 4},
+		{path: "pct:50,50,50,50/!10,10/0/default.png", requests: 1, tolerance: 8},
+		{path: "30,30,40,20/^80,40/!90/gray.png", requests: 6, tolerance: 8},
+	} {
+		atomic.StoreInt32(&requests, 0)
+
+		params := mustResolve(t, info, tc.path)
+
+		actual, err := stitcher.Stitch(context.Background(), info, params)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.path, err)
+		}
+
+		expected, err := render.Renderer{}.Render(exampleSourceImage(), params)
+		if err != nil {
+			t.Fatalf("%s: expected `nil` but got: %v", tc.path, err)
+		}
+
+		if _e, _a := expected.Bounds().Size(), actual.Bounds().Size(); _e != _a {
+			t.Fatalf("%s: expected `%v` but got: %v", tc.path, _e, _a)
+		} else if _e, _a := tc.requests, atomic.LoadInt32(&requests); _e != _a {
+			t.Fatalf("%s: expected `%v` requests but got: %v", tc.path, _e, _a)
+		} else if d := maxChannelDifference(expected, actual); d > tc.tolerance {
+			t.Fatalf("%s: expected difference <= %d but got: %d", tc.path, tc.tolerance, d)
+		}
+	}
+}
+
+func TestStitcher_Stitch_Service(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	server := httptest.NewServer(http.StripPrefix("/iiif", static.NewHandler(fsys, static.HandlerOptions{})))
+	defer server.Close()
+
+	info := exampleImageInformation(server.URL + "/iiif/example")
+
+	for path, file := range exampleTreeFS(t, info) {
+		fsys["example/"+path] = file
+	}
+
+	service, err := client.NewClient(client.ClientOptions{}).GetService(context.Background(), info.ID)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	}
+
+	params := mustResolve(t, info, "20,10,70,40/35,20/0/default.png")
+
+	actual, err := Stitcher{Source: ServiceTileSource{Service: service}}.Stitch(context.Background(), service.ImageInformation(), params)
+	if err != nil {
+		t.Fatalf("expected `nil` but got: %v", err)
+	} else if _e, _a := image.Pt(35, 20), actual.Bounds().Size(); _e != _a {
+		t.Fatalf("expected `%v` but got: %v", _e, _a)
+	}
+}